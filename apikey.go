@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/theantichris/events-api/auth"
+)
+
+// NewAPIKey runs the "api-key NAME" subcommand, printing a new API key and the entry of API_KEYS
+// that accepts it. Only the hash is kept, so the key can't be printed again.
+func NewAPIKey(name string) error {
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Key:     ", key)
+	fmt.Println("API_KEYS:", name+":"+auth.HashKey(key))
+
+	return nil
+}
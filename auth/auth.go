@@ -0,0 +1,64 @@
+// Package auth authenticates the requests to the API.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Methods of authentication.
+const (
+	APIKeyMethod = "api-key"
+	JWTMethod    = "jwt"
+)
+
+// Principal is who made an authenticated request.
+type Principal struct {
+	Subject string `json:"subject"` // the name of the API key, or the subject of the token
+	Method  string `json:"method"`
+}
+
+// String returns the Method and Subject of the Principal, e.g. api-key:kiosk, so API keys and
+// tokens with the same name are told apart.
+func (p *Principal) String() string {
+	return p.Method + ":" + p.Subject
+}
+
+// ErrNoCredentials is returned by Authenticators for requests without their kind of credentials.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator authenticates requests with one kind of credentials.
+type Authenticator interface {
+	// Authenticate returns the Principal of the request, ErrNoCredentials when the request doesn't
+	// have the Authenticator's kind of credentials, or why its credentials are invalid.
+	Authenticate(request *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+type accessTokenKey struct{}
+
+// WithAccessToken returns a copy of ctx whose request may send its bearer token in the
+// AccessTokenParameter.
+func WithAccessToken(ctx context.Context) context.Context {
+	return context.WithValue(ctx, accessTokenKey{}, true)
+}
+
+func accessTokenAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(accessTokenKey{}).(bool)
+
+	return allowed
+}
+
+// WithPrincipal returns a copy of ctx holding the Principal of its request.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the Principal of the request of ctx, or false when it's unauthenticated.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return principal, ok
+}
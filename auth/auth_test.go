@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeys(t *testing.T) {
+	keys, err := NewAPIKeys("kiosk:" + HashKey("secret") + ", dashboard:" + HashKey("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = keys.Authenticate(request)
+	assert.Equal(t, ErrNoCredentials, err)
+
+	request.Header.Set(APIKeyHeader, "secret")
+	principal, err := keys.Authenticate(request)
+	assert.Nil(t, err)
+	assert.Equal(t, &Principal{Subject: "kiosk", Method: APIKeyMethod}, principal)
+
+	request.Header.Set(APIKeyHeader, "wrong")
+	_, err = keys.Authenticate(request)
+	assert.NotNil(t, err)
+
+	for _, list := range []string{"kiosk", "kiosk:abc", "kiosk:" + HashKey("a") + ",kiosk:" + HashKey("b")} {
+		_, err := NewAPIKeys(list)
+		assert.NotNil(t, err, list)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "one",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := NewJWT(path, "https://issuer", "events-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(kid string, claims jwt.RegisteredClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return signed
+	}
+
+	valid := jwt.RegisteredClaims{
+		Subject:   "alice",
+		Issuer:    "https://issuer",
+		Audience:  jwt.ClaimStrings{"events-api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = authenticator.Authenticate(request)
+	assert.Equal(t, ErrNoCredentials, err)
+
+	request.Header.Set("Authorization", "Bearer "+sign("one", valid))
+	principal, err := authenticator.Authenticate(request)
+	assert.Nil(t, err)
+	assert.Equal(t, &Principal{Subject: "alice", Method: JWTMethod}, principal)
+
+	// The query parameter is only read where it's allowed.
+	request = httptest.NewRequest(http.MethodGet, "/?"+AccessTokenParameter+"="+sign("one", valid), nil)
+	_, err = authenticator.Authenticate(request)
+	assert.Equal(t, ErrNoCredentials, err)
+
+	principal, err = authenticator.Authenticate(request.WithContext(WithAccessToken(request.Context())))
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	otherIssuer := valid
+	otherIssuer.Issuer = "https://other"
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	tokens := map[string]string{
+		"expired":      sign("one", expired),
+		"other issuer": sign("one", otherIssuer),
+		"no expiry":    sign("one", noExpiry),
+		"unknown key":  sign("two", valid),
+		"unsigned":     "e30.e30.",
+	}
+
+	for name, token := range tokens {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		_, err := authenticator.Authenticate(request)
+		assert.NotNil(t, err, name)
+		assert.NotEqual(t, ErrNoCredentials, err, name)
+	}
+}
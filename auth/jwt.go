@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// AccessTokenParameter holds the bearer token of requests that can't send an Authorization
+// header, such as those of EventSource and browser WebSockets. It's only read from the requests
+// whose context allows it with WithAccessToken, since URLs end up in logs and browser history.
+const AccessTokenParameter = "access_token"
+
+// validMethods are the signing methods accepted, only ones verified with a public key.
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWT authenticates requests with JWT bearer tokens signed by one of the keys of a JWKS file.
+type JWT struct {
+	keys     map[string]crypto.PublicKey // by key ID
+	algs     map[string]string           // the alg of the keys that have one, by key ID
+	issuer   string
+	audience string
+	parser   *jwt.Parser
+}
+
+// NewJWT returns a JWT authenticator for the JSON Web Key Set in the file at path. When issuer or
+// audience aren't empty, tokens must have been issued by and for them.
+func NewJWT(path, issuer, audience string) (*JWT, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("unable to parse JWKS %s: %w", path, err)
+	}
+
+	j := &JWT{
+		keys:     map[string]crypto.PublicKey{},
+		algs:     map[string]string{},
+		issuer:   issuer,
+		audience: audience,
+		parser:   jwt.NewParser(jwt.WithValidMethods(validMethods)),
+	}
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse key %q of JWKS %s: %w", key.Kid, path, err)
+		}
+
+		j.keys[key.Kid] = publicKey
+		if key.Alg != "" {
+			j.algs[key.Kid] = key.Alg
+		}
+	}
+
+	if len(j.keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s has no signing keys", path)
+	}
+
+	return j, nil
+}
+
+// Authenticate returns the Principal of the subject of the request's bearer token.
+func (j *JWT) Authenticate(request *http.Request) (*Principal, error) {
+	var token string
+	if accessTokenAllowed(request.Context()) {
+		token = request.URL.Query().Get(AccessTokenParameter)
+	}
+
+	if header := request.Header.Get("Authorization"); header != "" {
+		parts := strings.SplitN(header, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			return nil, ErrNoCredentials
+		}
+
+		token = strings.TrimSpace(parts[1])
+	}
+
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if _, err := j.parser.ParseWithClaims(token, claims, j.key); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.ExpiresAt == nil:
+		return nil, fmt.Errorf("token has no expiry")
+	case claims.Subject == "":
+		return nil, fmt.Errorf("token has no subject")
+	case j.issuer != "" && !claims.VerifyIssuer(j.issuer, true):
+		return nil, fmt.Errorf("token has issuer %q", claims.Issuer)
+	case j.audience != "" && !claims.VerifyAudience(j.audience, true):
+		return nil, fmt.Errorf("token isn't for %q", j.audience)
+	}
+
+	return &Principal{Subject: claims.Subject, Method: JWTMethod}, nil
+}
+
+// key returns the key that signed the token, which is the only key when it names none.
+func (j *JWT) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" && len(j.keys) == 1 {
+		for id := range j.keys {
+			kid = id
+		}
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	if alg, ok := j.algs[kid]; ok && alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q isn't for %s", kid, token.Method.Alg())
+	}
+
+	return key, nil
+}
+
+// jwk is a JSON Web Key, RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP keys, OKP keys only have an X.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point isn't on curve %s", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported %s key", k.Crv)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
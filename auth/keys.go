@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader holds the API key of a request.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys authenticates requests with static API keys, which are only known by their SHA-256
+// hashes.
+type APIKeys struct {
+	hashes map[string][]byte // by name
+}
+
+// NewAPIKeys returns the APIKeys of a comma separated list of names and hex SHA-256 hashes, such
+// as "kiosk:9f86d0...,dashboard:60303a...".
+func NewAPIKeys(list string) (*APIKeys, error) {
+	keys := &APIKeys{hashes: map[string][]byte{}}
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("API key %q should be a name and a hash separated by a colon", entry)
+		}
+
+		hash, err := hex.DecodeString(parts[1])
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %q should have a hex SHA-256 hash", parts[0])
+		}
+
+		if _, ok := keys.hashes[parts[0]]; ok {
+			return nil, fmt.Errorf("API key %q is listed twice", parts[0])
+		}
+
+		keys.hashes[parts[0]] = hash
+	}
+
+	return keys, nil
+}
+
+// Authenticate returns the Principal named after the API key of the request.
+func (k *APIKeys) Authenticate(request *http.Request) (*Principal, error) {
+	key := request.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	// Compare with every key so the time taken doesn't tell which one was close.
+	name := ""
+	for n, h := range k.hashes {
+		if subtle.ConstantTimeCompare(hash[:], h) == 1 {
+			name = n
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("unknown API key")
+	}
+
+	return &Principal{Subject: name, Method: APIKeyMethod}, nil
+}
+
+// HashKey returns the hex SHA-256 hash of an API key, as listed for NewAPIKeys.
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// NewAPIKey returns a new random API key.
+func NewAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -24,11 +24,76 @@ var (
 		Message: "Error invalid argument.",
 	}
 
+	ErrUnauthorized = &Error{
+		Code:    http.StatusUnauthorized,
+		Message: "A valid API key or bearer token is required.",
+	}
+
+	ErrForbidden = &Error{
+		Code:    http.StatusForbidden,
+		Message: "Only admins are allowed to do this.",
+	}
+
 	ErrEventNotFound = &Error{
 		Code:    http.StatusNotFound,
 		Message: "Event not found.",
 	}
 
+	ErrOccurrenceNotFound = &Error{
+		Code:    http.StatusNotFound,
+		Message: "Event has no occurrence at that time.",
+	}
+
+	ErrFeedNotFound = &Error{
+		Code:    http.StatusNotFound,
+		Message: "Feed not found or revoked.",
+	}
+
+	ErrSubscriptionNotFound = &Error{
+		Code:    http.StatusNotFound,
+		Message: "Subscription not found.",
+	}
+
+	ErrWebhookNotFound = &Error{
+		Code:    http.StatusNotFound,
+		Message: "Webhook not found.",
+	}
+
+	ErrDeliveryNotFound = &Error{
+		Code:    http.StatusNotFound,
+		Message: "Delivery not found or not dead.",
+	}
+
+	ErrEventNotRecurring = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Event does not recur.",
+	}
+
+	ErrInvalidRecurrence = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Recurrence should be a valid RFC 5545 RRULE such as FREQ=WEEKLY;COUNT=10.",
+	}
+
+	ErrRecurrenceTooLong = &Error{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "Recurrence has too many occurrences before the time range to expand.",
+	}
+
+	ErrTimeRangeIsRequired = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "A from and to time range is required.",
+	}
+
+	ErrSubscriptionIsRequired = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Subscription name should be provided.",
+	}
+
+	ErrInvalidSocketRequest = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Request type should be subscribe or unsubscribe.",
+	}
+
 	ErrObjectIsRequired = &Error{
 		Code:    http.StatusBadRequest,
 		Message: "Request object should be provided.",
@@ -39,6 +104,36 @@ var (
 		Message: "A valid event ID is required.",
 	}
 
+	ErrEventUIDIsRequired = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Event UID is required.",
+	}
+
+	ErrInvalidCalendar = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Body should be an RFC 5545 VCALENDAR.",
+	}
+
+	ErrInvalidBatch = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "A batch should have 1 to 1000 operations of create, update, cancel or delete.",
+	}
+
+	ErrBatchAborted = &Error{
+		Code:    http.StatusFailedDependency,
+		Message: "Operation was not applied because another operation in the batch failed.",
+	}
+
+	ErrEventNameIsRequired = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Event name is required.",
+	}
+
+	ErrUnsupportedMediaType = &Error{
+		Code:    http.StatusUnsupportedMediaType,
+		Message: "Content-Type should be application/merge-patch+json.",
+	}
+
 	ErrEventTimingIsRequired = &Error{
 		Code:    http.StatusBadRequest,
 		Message: "Event start time and end time are required.",
@@ -46,7 +141,72 @@ var (
 
 	ErrInvalidLimit = &Error{
 		Code:    http.StatusBadRequest,
-		Message: "Limit should be an integral value.",
+		Message: "Limit should be a non-negative integral value.",
+	}
+
+	ErrPreconditionFailed = &Error{
+		Code:    http.StatusPreconditionFailed,
+		Message: "Event has been modified since it was retrieved.",
+	}
+
+	ErrSubscriptionExists = &Error{
+		Code:    http.StatusConflict,
+		Message: "Subscription already exists on this connection.",
+	}
+
+	ErrPreconditionRequired = &Error{
+		Code:    http.StatusPreconditionRequired,
+		Message: "An If-Match header with the event ETag is required.",
+	}
+
+	ErrInvalidStatus = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Status should be one of draft, original, published, rescheduled, postponed, canceled or completed.",
+	}
+
+	ErrRequestTooLarge = &Error{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "Request body is too large.",
+	}
+
+	ErrEventUIDExists = &Error{
+		Code:    http.StatusConflict,
+		Message: "An event with this UID already exists.",
+	}
+
+	ErrInvalidTransition = &Error{
+		Code:    http.StatusConflict,
+		Message: "Event can't change to that status from its current status.",
+	}
+
+	ErrInvalidSort = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Sort should be one of id, start, end, name, created-at or relevance with q, prefixed with - for descending.",
+	}
+
+	ErrInvalidSearch = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Search should contain at least one word.",
+	}
+
+	ErrInvalidCursor = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Cursor is invalid or was issued for a different sort.",
+	}
+
+	ErrInvalidTimeRange = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "The start of a time range should be before its end.",
+	}
+
+	ErrInvalidWebhookURL = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Webhook URL should be an absolute http or https URL.",
+	}
+
+	ErrInvalidOperation = &Error{
+		Code:    http.StatusBadRequest,
+		Message: "Operations should be any of create, update, patch, cancel, reschedule, transition, uncancel, delete, restore, purge, cancel-occurrence or reschedule-occurrence.",
 	}
 
 	ErrInvalidTimeFormat = &Error{
@@ -1,11 +1,18 @@
 module github.com/theantichris/events-api
 
-go 1.15
+go 1.16
 
 require (
+	github.com/golang-jwt/jwt/v4 v4.5.2
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/jackc/pgconn v1.7.0
+	github.com/jackc/pgx/v4 v4.9.0
 	github.com/joho/godotenv v1.3.0
+	github.com/mattn/go-sqlite3 v1.14.3
 	github.com/stretchr/testify v1.5.1
+	github.com/teambition/rrule-go v1.8.2
 	gorm.io/driver/postgres v1.0.5
+	gorm.io/driver/sqlite v1.1.3
 	gorm.io/gorm v1.20.6
 )
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/theantichris/events-api/auth"
+	"github.com/theantichris/events-api/errors"
+)
+
+// FeedRoute names the route of feeds, which is served without authentication since calendar
+// clients can't send credentials, and the token in its URL already grants access.
+const FeedRoute = "feed"
+
+// StreamRoute and SocketRoute name the routes of streams and WebSockets, which accept bearer
+// tokens in the auth.AccessTokenParameter since EventSource and browser WebSockets can't send
+// an Authorization header.
+const (
+	StreamRoute = "stream"
+	SocketRoute = "socket"
+)
+
+// Authenticate is middleware that puts the Principal authenticated by the first of the
+// authenticators whose credentials the request has on its context, and rejects requests with no
+// or invalid credentials with 401 Unauthorized. Every request passes when there are no
+// authenticators.
+func Authenticate(authenticators ...auth.Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if len(authenticators) == 0 {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			if route := mux.CurrentRoute(request); route != nil {
+				switch route.GetName() {
+				case FeedRoute:
+					next.ServeHTTP(writer, request)
+					return
+				case StreamRoute, SocketRoute:
+					request = request.WithContext(auth.WithAccessToken(request.Context()))
+				}
+			}
+
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(request)
+				if err == auth.ErrNoCredentials {
+					continue
+				}
+
+				if err != nil {
+					log.Println("Unable to authenticate:", err)
+					break
+				}
+
+				next.ServeHTTP(writer, request.WithContext(auth.WithPrincipal(request.Context(), principal)))
+
+				return
+			}
+
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="events-api"`)
+			WriteError(writer, errors.ErrUnauthorized)
+		})
+	}
+}
+
+// Admin wraps the handler of an admin route, such as the trash and webhooks, so only the
+// authenticated Principals that are one of the admins, as named by their String, are let through,
+// and other requests get 403 Forbidden.
+func Admin(admins ...string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if principal, ok := auth.FromContext(request.Context()); ok {
+				for _, admin := range admins {
+					if principal.String() == admin {
+						next(writer, request)
+						return
+					}
+				}
+			}
+
+			WriteError(writer, errors.ErrForbidden)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+// Batch applies an array of create, update, cancel and delete operations and reports the outcome
+// of each. Atomic batches respond with the status code of the failed operation, if any.
+func (h handler) Batch(writer http.ResponseWriter, request *http.Request) {
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	batchRequest := &objects.BatchRequest{}
+	if UnmarshalStrict(writer, data, batchRequest) != nil {
+		return
+	}
+
+	if batchRequest.Mode != "" && batchRequest.Mode != objects.BatchAtomic && batchRequest.Mode != objects.BatchBestEffort {
+		WriteError(writer, errors.ErrInvalidBatch)
+		return
+	}
+
+	if len(batchRequest.Operations) == 0 || len(batchRequest.Operations) > objects.MaxBatchOperations {
+		WriteError(writer, errors.ErrInvalidBatch)
+		return
+	}
+
+	results := make([]*objects.ItemResult, len(batchRequest.Operations))
+
+	// Only valid operations reach the store, valid holds their index in the request.
+	var valid []int
+	var operations []*objects.BatchOperation
+
+	for i, operation := range batchRequest.Operations {
+		if err := h.checkOperation(operation); err != nil {
+			results[i] = &objects.ItemResult{Index: i, ID: operationID(operation)}
+			results[i].Fail(err)
+
+			continue
+		}
+
+		valid = append(valid, i)
+		operations = append(operations, operation)
+	}
+
+	response := &objects.EventResponse{Results: results}
+
+	if batchRequest.Atomic() && len(operations) < len(batchRequest.Operations) {
+		for i, result := range results {
+			if result == nil {
+				results[i] = &objects.ItemResult{Index: i, ID: operationID(batchRequest.Operations[i])}
+				results[i].Fail(errors.ErrBatchAborted)
+			} else if response.Code == 0 {
+				response.Code = result.Code
+			}
+		}
+
+		WriteResponse(writer, response)
+		return
+	}
+
+	if len(operations) > 0 {
+		applied, err := h.store.Batch(request.Context(), objects.BatchRequest{Mode: batchRequest.Mode, Operations: operations})
+		if err != nil {
+			WriteError(writer, err)
+			return
+		}
+
+		for j, result := range applied {
+			result.Index = valid[j]
+			results[valid[j]] = result
+
+			if batchRequest.Atomic() && response.Code == 0 && result.Status == objects.ItemFailed && result.Error != errors.ErrBatchAborted {
+				response.Code = result.Code
+			}
+		}
+	}
+
+	WriteResponse(writer, response)
+}
+
+// checkOperation returns why an operation can't be applied.
+func (h handler) checkOperation(operation *objects.BatchOperation) error {
+	if operation == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	switch operation.Op {
+	case objects.BatchCreate:
+		if operation.Event == nil {
+			return errors.ErrObjectIsRequired
+		}
+
+		// UIDs are only given to Events by importing iCalendar data.
+		operation.Event.UID = ""
+
+		if err := checkSlot(operation.Event.TimeSlot); err != nil {
+			return err
+		}
+
+		return checkRecurrence(operation.Event)
+	case objects.BatchUpdate, objects.BatchCancel, objects.BatchDelete:
+		if operation.ID == "" {
+			return errors.ErrValidEventIDIsRequired
+		}
+
+		if operation.Op == objects.BatchUpdate && operation.Event == nil {
+			return errors.ErrObjectIsRequired
+		}
+
+		if operation.Op == objects.BatchUpdate && operation.Event.Name == "" {
+			return errors.ErrEventNameIsRequired
+		}
+
+		if h.options.RequireIfMatch && operation.Version == 0 {
+			return errors.ErrPreconditionRequired
+		}
+
+		return nil
+	}
+
+	return errors.ErrInvalidBatch
+}
+
+func operationID(operation *objects.BatchOperation) string {
+	if operation == nil {
+		return ""
+	}
+
+	return operation.ID
+}
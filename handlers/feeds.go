@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+func (h handler) CreateFeed(writer http.ResponseWriter, request *http.Request) {
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	feed := &objects.Feed{}
+
+	if UnmarshalStrict(writer, data, feed) != nil {
+		return
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(feed.Filter, "?"))
+	if err != nil {
+		WriteError(writer, errors.ErrBadRequest)
+		return
+	}
+
+	// Validate the filter now rather than on every poll of the Feed.
+	if _, err := ListRequestFromQuery(writer, values); err != nil {
+		return
+	}
+
+	feed.Token = ""
+	feed.Filter = values.Encode()
+
+	if err := h.store.CreateFeed(request.Context(), objects.CreateFeedRequest{Feed: feed}); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	feed.URL = strings.TrimSuffix(request.URL.Path, "/") + "/" + feed.Token + ".ics"
+
+	WriteResponse(writer, &objects.EventResponse{Feed: feed})
+}
+
+func (h handler) RevokeFeed(writer http.ResponseWriter, request *http.Request) {
+	token := mux.Vars(request)["token"]
+
+	if err := h.store.RevokeFeed(request.Context(), objects.RevokeFeedRequest{Token: token}); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+// Feed serves the events of a saved list as iCalendar data, answering polls that send the
+// ETag or Last-Modified of the previous response with 304 Not Modified while nothing changed.
+func (h handler) Feed(writer http.ResponseWriter, request *http.Request) {
+	feed, err := h.store.GetFeed(request.Context(), objects.GetFeedRequest{Token: mux.Vars(request)["token"]})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	values, err := url.ParseQuery(feed.Filter)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	listRequest, err := ListRequestFromQuery(writer, values)
+	if err != nil {
+		return
+	}
+
+	events, err := h.listAll(request.Context(), listRequest)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	etag, lastModified := feedValidators(feed, events)
+
+	if etag != feed.ETag {
+		// An Event left the list when the rest weren't changed since, so date the change now, and
+		// at least a second later since HTTP dates are only that precise.
+		if !lastModified.After(feed.ModifiedAt) {
+			lastModified = time.Now()
+
+			if next := feed.ModifiedAt.Truncate(time.Second).Add(time.Second); lastModified.Before(next) {
+				lastModified = next
+			}
+		}
+
+		updateRequest := objects.UpdateFeedRequest{Token: feed.Token, ETag: etag, ModifiedAt: lastModified}
+		if err := h.store.UpdateFeed(request.Context(), updateRequest); err != nil {
+			WriteError(writer, err)
+			return
+		}
+	} else if feed.ModifiedAt.After(lastModified) {
+		lastModified = feed.ModifiedAt
+	}
+
+	writer.Header().Set("ETag", etag)
+	writer.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	if notModified(request, etag, lastModified) {
+		writer.Header().Del("Content-Type")
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeCalendar(writer, request.Context(), events)
+}
+
+// listAll pages through every Event of the list, or up to its limit when it has one.
+func (h handler) listAll(ctx context.Context, listRequest objects.ListRequest) ([]*objects.Event, error) {
+	limit := listRequest.Limit
+
+	listRequest.Limit = 0
+	listRequest.SkipTotal = true
+
+	var events []*objects.Event
+
+	for {
+		page, meta, err := h.store.List(ctx, listRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page...)
+
+		if limit > 0 && len(events) >= limit {
+			return events[:limit], nil
+		}
+
+		if !meta.HasMore {
+			return events, nil
+		}
+
+		cursor, ok := objects.DecodeCursor(meta.NextCursor)
+		if !ok {
+			return nil, errors.ErrInvalidCursor
+		}
+
+		listRequest.Cursor = cursor
+		listRequest.Sort = cursor.Sort
+	}
+}
+
+// feedValidators returns the ETag and Last-Modified time of a Feed's events. Any change to the
+// events, including rescheduling, canceling or removing one from the list, changes the ETag.
+func feedValidators(feed *objects.Feed, events []*objects.Event) (string, time.Time) {
+	hash := sha256.New()
+	lastModified := feed.CreatedAt
+
+	for _, event := range events {
+		_, _ = fmt.Fprintf(hash, "%s\n%d\n", event.ID, event.Version)
+
+		for _, t := range []time.Time{event.UpdatedAt, event.RescheduledAt, event.CanceledAt} {
+			_, _ = fmt.Fprintf(hash, "%d\n", t.UnixNano())
+
+			if t.After(lastModified) {
+				lastModified = t
+			}
+		}
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, lastModified
+}
+
+// notModified reports whether the conditional headers of a GET request still match. If-None-Match
+// takes precedence over If-Modified-Since, as in RFC 7232.
+func notModified(request *http.Request, etag string, lastModified time.Time) bool {
+	if header := request.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(request.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates only have second precision.
+	return !lastModified.Truncate(time.Second).After(since)
+}
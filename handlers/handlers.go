@@ -3,11 +3,14 @@ package handlers
 import (
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/theantichris/events-api/errors"
 	"github.com/theantichris/events-api/objects"
 
 	"github.com/theantichris/events-api/store"
+	"github.com/theantichris/events-api/stream"
 )
 
 // EventHandler defines the contract for all the handlers.
@@ -16,57 +19,102 @@ type EventHandler interface {
 	List(w http.ResponseWriter, r *http.Request)
 	Create(w http.ResponseWriter, r *http.Request)
 	Update(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
 	Cancel(w http.ResponseWriter, r *http.Request)
 	Reschedule(w http.ResponseWriter, r *http.Request)
+	Publish(w http.ResponseWriter, r *http.Request)
+	Postpone(w http.ResponseWriter, r *http.Request)
+	Complete(w http.ResponseWriter, r *http.Request)
+	Uncancel(w http.ResponseWriter, r *http.Request)
 	Delete(w http.ResponseWriter, r *http.Request)
+	Restore(w http.ResponseWriter, r *http.Request)
+	Trash(w http.ResponseWriter, r *http.Request)
+	History(w http.ResponseWriter, r *http.Request)
+	Occurrences(w http.ResponseWriter, r *http.Request)
+	CancelOccurrence(w http.ResponseWriter, r *http.Request)
+	RescheduleOccurrence(w http.ResponseWriter, r *http.Request)
+	GetCalendar(w http.ResponseWriter, r *http.Request)
+	ListCalendar(w http.ResponseWriter, r *http.Request)
+	CreateFeed(w http.ResponseWriter, r *http.Request)
+	RevokeFeed(w http.ResponseWriter, r *http.Request)
+	Feed(w http.ResponseWriter, r *http.Request)
+	Import(w http.ResponseWriter, r *http.Request)
+	Batch(w http.ResponseWriter, r *http.Request)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	ListWebhooks(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	Deliveries(w http.ResponseWriter, r *http.Request)
+	RetryDelivery(w http.ResponseWriter, r *http.Request)
+	Stream(w http.ResponseWriter, r *http.Request)
+	Socket(w http.ResponseWriter, r *http.Request)
+}
+
+// Options configures an EventHandler.
+type Options struct {
+	// RequireIfMatch rejects changes to an Event that don't send its ETag in an If-Match header.
+	RequireIfMatch bool
+
+	// PingInterval is how often WebSocket clients are pinged, they're disconnected when they don't
+	// answer within twice of it, and how often streams send keep-alive comments. Defaults to 30s.
+	PingInterval time.Duration
 }
 
 type handler struct {
-	store store.EventStore
+	store   store.EventStore
+	options Options
+	broker  *stream.Broker
 }
 
 // NewEventHandler creates and returns a new EventHandler.
-func NewEventHandler(store store.EventStore) EventHandler {
-	return &handler{store}
+func NewEventHandler(store store.EventStore, options Options) EventHandler {
+	broker := stream.NewBroker(stream.DefaultBufferSize)
+	store.Listen(broker.Publish)
+
+	return &handler{store, options, broker}
 }
 
 func (h handler) Get(writer http.ResponseWriter, request *http.Request) {
-	id := request.URL.Query().Get("id")
+	id := EventID(request)
 
 	if id == "" {
 		WriteError(writer, errors.ErrValidEventIDIsRequired)
 		return
 	}
 
-	event, err := h.store.Get(request.Context(), objects.GetRequest{ID: id})
+	asOf, err := TimeFromString(writer, request.URL.Query().Get("as-of"))
+	if err != nil {
+		return
+	}
+
+	event, err := h.store.Get(request.Context(), objects.GetRequest{ID: id, AsOf: asOf})
 	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	// A past version of the Event can't be changed, so it has no ETag to match.
+	if asOf.IsZero() {
+		writer.Header().Set("ETag", ETag(event.Version))
+	}
+
 	WriteResponse(writer, &objects.EventResponse{Event: event})
 }
 
 func (h handler) List(writer http.ResponseWriter, request *http.Request) {
-	values := request.URL.Query()
-	after := values.Get("after")
-	name := values.Get("name")
-	limit, err := IntFromString(writer, values.Get("limit"))
+	listRequest, err := ListRequestFromQuery(writer, request.URL.Query())
 	if err != nil {
 		return
 	}
 
-	events, err := h.store.List(request.Context(), objects.ListRequest{
-		Limit: limit,
-		After: after,
-		Name:  name,
-	})
+	events, meta, err := h.store.List(request.Context(), listRequest)
 	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	WriteResponse(writer, &objects.EventResponse{Events: events})
+	WriteResponse(writer, &objects.EventResponse{Events: events, Meta: meta, NextCursor: meta.NextCursor})
 }
 
 func (h handler) Create(writer http.ResponseWriter, request *http.Request) {
@@ -83,11 +131,19 @@ func (h handler) Create(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// UIDs are only given to Events by importing iCalendar data.
+	event.UID = ""
+
 	if err := checkSlot(event.TimeSlot); err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	if err := checkRecurrence(event); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
 	if err = h.store.Create(request.Context(), objects.CreateRequest{Event: event}); err != nil {
 		WriteError(writer, err)
 		return
@@ -108,36 +164,113 @@ func (h handler) Update(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	if _, err := h.store.Get(request.Context(), objects.GetRequest{ID: updateRequest.ID}); err != nil {
+	if id := EventID(request); id != "" {
+		updateRequest.ID = id
+	}
+
+	if updateRequest.ID == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
+	// PUT replaces the details, so like PATCH it can't leave the Event without a name. The
+	// deprecated route, which takes the ID in the body, keeps accepting an empty name.
+	if mux.Vars(request)["id"] != "" && updateRequest.Name == "" {
+		WriteError(writer, errors.ErrEventNameIsRequired)
+		return
+	}
+
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	if err = h.store.Update(request.Context(), *updateRequest); err != nil {
+	event, err := h.change(request.Context(), updateRequest.ID, precondition, func(tx store.EventStore, _ *objects.Event, version int) error {
+		updateRequest.Version = version
+
+		return tx.Update(request.Context(), *updateRequest)
+	})
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	writer.Header().Set("ETag", ETag(event.Version))
+
 	WriteResponse(writer, &objects.EventResponse{})
 }
 
+func (h handler) Patch(writer http.ResponseWriter, request *http.Request) {
+	id := EventID(request)
+
+	if contentType := request.Header.Get("Content-Type"); !isMergePatch(contentType) {
+		WriteError(writer, errors.ErrUnsupportedMediaType)
+		return
+	}
+
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	patchRequest := &objects.PatchRequest{}
+	if UnmarshalStrict(writer, data, patchRequest) != nil {
+		return
+	}
+
+	patchRequest.ID = id
+
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	event, err := h.change(request.Context(), id, precondition, func(tx store.EventStore, current *objects.Event, version int) error {
+		patchRequest.Version = version
+		patchRequest.Apply(current)
+
+		if current.Name == "" {
+			return errors.ErrEventNameIsRequired
+		}
+
+		return tx.Patch(request.Context(), *patchRequest)
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	WriteResponse(writer, &objects.EventResponse{Event: event})
+}
+
 func (h handler) Cancel(writer http.ResponseWriter, request *http.Request) {
-	id := request.URL.Query().Get("id")
+	id := EventID(request)
 	if id == "" {
 		WriteError(writer, errors.ErrValidEventIDIsRequired)
 		return
 	}
 
-	if _, err := h.store.Get(request.Context(), objects.GetRequest{ID: id}); err != nil {
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	if err := h.store.Cancel(request.Context(), objects.CancelRequest{ID: id}); err != nil {
+	event, err := h.change(request.Context(), id, precondition, func(tx store.EventStore, _ *objects.Event, version int) error {
+		return tx.Cancel(request.Context(), objects.CancelRequest{ID: id, Version: version})
+	})
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	writer.Header().Set("ETag", ETag(event.Version))
+
 	WriteResponse(writer, &objects.EventResponse{})
 }
 
@@ -154,40 +287,177 @@ func (h handler) Reschedule(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if id := EventID(request); id != "" {
+		rescheduleRequest.ID = id
+	}
+
+	if rescheduleRequest.ID == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
 	if err := checkSlot(rescheduleRequest.NewTimeSlot); err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	if _, err := h.store.Get(request.Context(), objects.GetRequest{ID: rescheduleRequest.ID}); err != nil {
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	if err := h.store.Reschedule(request.Context(), *rescheduleRequest); err != nil {
+	event, err := h.change(request.Context(), rescheduleRequest.ID, precondition, func(tx store.EventStore, _ *objects.Event, version int) error {
+		rescheduleRequest.Version = version
+
+		return tx.Reschedule(request.Context(), *rescheduleRequest)
+	})
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	writer.Header().Set("ETag", ETag(event.Version))
+
 	WriteResponse(writer, &objects.EventResponse{})
 }
 
 func (h handler) Delete(writer http.ResponseWriter, request *http.Request) {
-	id := request.URL.Query().Get("id")
+	id := EventID(request)
 	if id == "" {
 		WriteError(writer, errors.ErrValidEventIDIsRequired)
 		return
 	}
 
-	if _, err := h.store.Get(request.Context(), objects.GetRequest{ID: id}); err != nil {
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
-	if err := h.store.Delete(request.Context(), objects.DeleteRequest{ID: id}); err != nil {
+	var deleted *objects.Event
+
+	err = h.store.WithTx(request.Context(), func(tx store.EventStore) error {
+		current, err := tx.Get(request.Context(), objects.GetRequest{ID: id})
+		if err != nil {
+			return err
+		}
+
+		version, err := precondition.Check(current)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(request.Context(), objects.DeleteRequest{ID: id, Version: version}); err != nil {
+			return err
+		}
+
+		deleted, err = tx.Get(request.Context(), objects.GetRequest{ID: id, Deleted: true})
+
+		return err
+	})
+	if err != nil {
 		WriteError(writer, err)
 		return
 	}
 
+	// The ETag of the deleted Event is what restoring it matches.
+	writer.Header().Set("ETag", ETag(deleted.Version))
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+func (h handler) Occurrences(writer http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+
+	from, err := TimeFromString(writer, values.Get("from"))
+	if err != nil {
+		return
+	}
+
+	to, err := TimeFromString(writer, values.Get("to"))
+	if err != nil {
+		return
+	}
+
+	if from.IsZero() || to.IsZero() {
+		WriteError(writer, errors.ErrTimeRangeIsRequired)
+		return
+	}
+
+	if to.Before(from) {
+		WriteError(writer, errors.ErrInvalidTimeRange)
+		return
+	}
+
+	occurrences, err := h.store.ListOccurrences(request.Context(), objects.OccurrencesRequest{
+		ID:   EventID(request),
+		From: from,
+		To:   to,
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Occurrences: occurrences})
+}
+
+func (h handler) CancelOccurrence(writer http.ResponseWriter, request *http.Request) {
+	recurrenceID, err := TimeFromString(writer, mux.Vars(request)["recurrence-id"])
+	if err != nil {
+		return
+	}
+
+	id := EventID(request)
+
+	event, err := h.change(request.Context(), id, nil, func(tx store.EventStore, _ *objects.Event, _ int) error {
+		return tx.CancelOccurrence(request.Context(), objects.CancelOccurrenceRequest{ID: id, RecurrenceID: recurrenceID})
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+func (h handler) RescheduleOccurrence(writer http.ResponseWriter, request *http.Request) {
+	recurrenceID, err := TimeFromString(writer, mux.Vars(request)["recurrence-id"])
+	if err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	rescheduleRequest := &objects.RescheduleOccurrenceRequest{}
+	if Unmarshal(writer, data, rescheduleRequest) != nil {
+		return
+	}
+
+	if err := checkSlot(rescheduleRequest.NewTimeSlot); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	rescheduleRequest.ID = EventID(request)
+	rescheduleRequest.RecurrenceID = recurrenceID
+
+	event, err := h.change(request.Context(), rescheduleRequest.ID, nil, func(tx store.EventStore, _ *objects.Event, _ int) error {
+		return tx.RescheduleOccurrence(request.Context(), *rescheduleRequest)
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
 	WriteResponse(writer, &objects.EventResponse{})
 }
@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
 
 	"github.com/theantichris/events-api/errors"
 )
@@ -40,6 +47,10 @@ func IntFromString(writer http.ResponseWriter, v string) (int, error) {
 	}
 
 	response, err := strconv.Atoi(v)
+	if err == nil && response < 0 {
+		err = errors.ErrInvalidLimit
+	}
+
 	if err != nil {
 		log.Println(err)
 		WriteError(writer, errors.ErrInvalidLimit)
@@ -64,6 +75,160 @@ func Unmarshal(writer http.ResponseWriter, data []byte, v interface{}) error {
 	return err
 }
 
+// TimeFromString parses an optional RFC3339 time.
+func TimeFromString(writer http.ResponseWriter, v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	response, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		log.Println(err)
+		WriteError(writer, errors.ErrInvalidTimeFormat)
+	}
+
+	return response, err
+}
+
+// ListRequestFromQuery parses and validates the paging and filters of a ListRequest, writing the
+// error when they're invalid.
+func ListRequestFromQuery(writer http.ResponseWriter, values url.Values) (objects.ListRequest, error) {
+	listRequest, err := ParseListQuery(values)
+	if err != nil {
+		WriteError(writer, err)
+	}
+
+	return listRequest, err
+}
+
+// ParseListQuery parses and validates the paging and filters of a ListRequest.
+func ParseListQuery(values url.Values) (objects.ListRequest, error) {
+	listRequest := objects.ListRequest{
+		After:       values.Get("after"),
+		Name:        values.Get("name"),
+		Query:       values.Get("q"),
+		Address:     values.Get("address"),
+		Description: values.Get("description"),
+	}
+
+	if v := values.Get("limit"); v != "" {
+		var err error
+		if listRequest.Limit, err = strconv.Atoi(v); err != nil || listRequest.Limit < 0 {
+			return listRequest, errors.ErrInvalidLimit
+		}
+	}
+
+	if v := values.Get("total"); v != "" {
+		total, err := strconv.ParseBool(v)
+		if err != nil {
+			return listRequest, errors.ErrBadRequest
+		}
+
+		listRequest.SkipTotal = !total
+	}
+
+	var ok bool
+
+	if listRequest.Sort, ok = objects.ParseSort(values.Get("sort")); !ok {
+		return listRequest, errors.ErrInvalidSort
+	}
+
+	if listRequest.Query != "" && len(objects.SearchTerms(listRequest.Query)) == 0 {
+		return listRequest, errors.ErrInvalidSearch
+	}
+
+	// Searches are listed by relevance unless another sort is given.
+	if listRequest.Query != "" && values.Get("sort") == "" {
+		listRequest.Sort = objects.Sort{Field: objects.SortByRelevance, Descending: true}
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		if listRequest.Cursor, ok = objects.DecodeCursor(v); !ok {
+			return listRequest, errors.ErrInvalidCursor
+		}
+
+		// The cursor carries its sort, so sort only has to be repeated if given.
+		if values.Get("sort") != "" && listRequest.Cursor.Sort != listRequest.Sort {
+			return listRequest, errors.ErrInvalidCursor
+		}
+
+		listRequest.Sort = listRequest.Cursor.Sort
+	}
+
+	if listRequest.Sort.Field == objects.SortByRelevance && listRequest.Query == "" {
+		return listRequest, errors.ErrInvalidSort
+	}
+
+	for _, value := range values["status"] {
+		for _, status := range strings.Split(value, ",") {
+			status := objects.EventStatus(strings.TrimSpace(status))
+			if !status.Valid() {
+				return listRequest, errors.ErrInvalidStatus
+			}
+
+			listRequest.Status = append(listRequest.Status, status)
+		}
+	}
+
+	for param, field := range map[string]*time.Time{
+		"from":           &listRequest.From,
+		"to":             &listRequest.To,
+		"created-after":  &listRequest.CreatedAfter,
+		"created-before": &listRequest.CreatedBefore,
+		"updated-after":  &listRequest.UpdatedAfter,
+		"updated-before": &listRequest.UpdatedBefore,
+	} {
+		if v := values.Get(param); v != "" {
+			var err error
+			if *field, err = time.Parse(time.RFC3339, v); err != nil {
+				return listRequest, errors.ErrInvalidTimeFormat
+			}
+		}
+	}
+
+	for _, bounds := range [][2]time.Time{
+		{listRequest.From, listRequest.To},
+		{listRequest.CreatedAfter, listRequest.CreatedBefore},
+		{listRequest.UpdatedAfter, listRequest.UpdatedBefore},
+	} {
+		if !bounds[0].IsZero() && !bounds[1].IsZero() && bounds[1].Before(bounds[0]) {
+			return listRequest, errors.ErrInvalidTimeRange
+		}
+	}
+
+	return listRequest, nil
+}
+
+// UnmarshalStrict is Unmarshal but rejects fields that v doesn't define.
+func UnmarshalStrict(writer http.ResponseWriter, data []byte, v interface{}) error {
+	if d := string(data); d == "null" || d == "" {
+		WriteError(writer, errors.ErrObjectIsRequired)
+
+		return errors.ErrObjectIsRequired
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		log.Println(err)
+		WriteError(writer, errors.ErrBadRequest)
+	}
+
+	return err
+}
+
+// isMergePatch reports whether the Content-Type is JSON Merge Patch, plain JSON is also accepted.
+func isMergePatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/merge-patch+json" || mediaType == "application/json"
+}
+
 func checkSlot(slot *objects.TimeSlot) error {
 	if slot == nil {
 		return errors.ErrEventTimingIsRequired
@@ -79,3 +244,111 @@ func checkSlot(slot *objects.TimeSlot) error {
 
 	return nil
 }
+
+// EventID returns the event ID from the URL path, falling back to the deprecated id query parameter.
+func EventID(request *http.Request) string {
+	if id := mux.Vars(request)["id"]; id != "" {
+		return id
+	}
+
+	return request.URL.Query().Get("id")
+}
+
+// ETag returns the entity tag for a version of an Event.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Precondition is the Event versions an If-Match header matches, nil when it matches any.
+type Precondition []int
+
+// IfMatch parses the If-Match header, a list of ETags or "*". Its ETags are compared strongly as
+// RFC 7232 requires, so weak ones never match.
+func IfMatch(request *http.Request, required bool) (Precondition, error) {
+	header := strings.TrimSpace(request.Header.Get("If-Match"))
+
+	if header == "" {
+		if required {
+			return nil, errors.ErrPreconditionRequired
+		}
+
+		return nil, nil
+	}
+
+	precondition := Precondition{}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" {
+			return nil, nil
+		}
+
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && version > 0 {
+			precondition = append(precondition, version)
+		}
+	}
+
+	return precondition, nil
+}
+
+// Check returns the version the change of the current Event must be made at, 0 when any version
+// matches, or ErrPreconditionFailed when none does.
+func (p Precondition) Check(current *objects.Event) (int, error) {
+	if p == nil {
+		return 0, nil
+	}
+
+	for _, version := range p {
+		if version == current.Version {
+			return version, nil
+		}
+	}
+
+	return 0, errors.ErrPreconditionFailed
+}
+
+// change makes a change to the Event in a transaction, at the version the precondition requires
+// of it, and returns the Event after the change.
+func (h handler) change(ctx context.Context, id string, precondition Precondition, fn func(tx store.EventStore, current *objects.Event, version int) error) (*objects.Event, error) {
+	var event *objects.Event
+
+	err := h.store.WithTx(ctx, func(tx store.EventStore) error {
+		current, err := tx.Get(ctx, objects.GetRequest{ID: id})
+		if err != nil {
+			return err
+		}
+
+		version, err := precondition.Check(current)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tx, current, version); err != nil {
+			return err
+		}
+
+		event, err = tx.Get(ctx, objects.GetRequest{ID: id})
+
+		return err
+	})
+
+	return event, err
+}
+
+func checkRecurrence(event *objects.Event) error {
+	if !event.Recurring() {
+		return nil
+	}
+
+	if err := event.CheckRecurrence(); err != nil {
+		log.Println(err)
+		return errors.ErrInvalidRecurrence
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/theantichris/events-api/auth"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// Anonymous is the actor of changes made by unauthenticated requests.
+const Anonymous = "anonymous"
+
+// Actor is middleware that records changes made by a request as made by its authenticated
+// Principal, or else by Anonymous. Clients can't name the actor themselves.
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		actor := Anonymous
+		if principal, ok := auth.FromContext(request.Context()); ok && principal.Subject != "" {
+			actor = principal.Subject
+		}
+
+		next.ServeHTTP(writer, request.WithContext(store.WithActor(request.Context(), actor)))
+	})
+}
+
+// History lists the changes of an Event, oldest first, paged by the ID of the last change.
+func (h handler) History(writer http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+	historyRequest := objects.HistoryRequest{ID: EventID(request)}
+
+	var err error
+
+	if historyRequest.Limit, err = IntFromString(writer, values.Get("limit")); err != nil {
+		return
+	}
+
+	if v := values.Get("after"); v != "" {
+		if historyRequest.After, err = strconv.ParseInt(v, 10, 64); err != nil {
+			WriteError(writer, errors.ErrInvalidCursor)
+			return
+		}
+	}
+
+	history, meta, err := h.store.History(request.Context(), historyRequest)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{History: history, Meta: meta, NextCursor: meta.NextCursor})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/ical"
+	"github.com/theantichris/events-api/objects"
+)
+
+func (h handler) GetCalendar(writer http.ResponseWriter, request *http.Request) {
+	id := EventID(request)
+
+	if id == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
+	event, err := h.store.Get(request.Context(), objects.GetRequest{ID: id})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	h.writeCalendar(writer, request.Context(), []*objects.Event{event})
+}
+
+func (h handler) ListCalendar(writer http.ResponseWriter, request *http.Request) {
+	listRequest, err := ListRequestFromQuery(writer, request.URL.Query())
+	if err != nil {
+		return
+	}
+
+	events, _, err := h.store.List(request.Context(), listRequest)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	h.writeCalendar(writer, request.Context(), events)
+}
+
+// writeCalendar writes the events, along with the overrides of their occurrences, as iCalendar data.
+func (h handler) writeCalendar(writer http.ResponseWriter, ctx context.Context, events []*objects.Event) {
+	var ids []string
+	for _, event := range events {
+		if event.Recurring() {
+			ids = append(ids, event.ID)
+		}
+	}
+
+	overrides, err := h.store.ListOverrides(ctx, objects.OverridesRequest{IDs: ids})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := ical.Encode(buf, events, overrides); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", ical.ContentType)
+	writer.WriteHeader(http.StatusOK)
+
+	_, _ = writer.Write(buf.Bytes())
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/ical"
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// maxImportSize is the largest iCalendar body imported at once.
+const maxImportSize = 10 << 20
+
+// Import creates or updates Events from the VEVENTs of a VCALENDAR body, matching them to
+// Events by UID, and reports the outcome of each VEVENT in order.
+func (h handler) Import(writer http.ResponseWriter, request *http.Request) {
+	// The body is only unreadable when it's too large, or the client is gone.
+	data, err := ioutil.ReadAll(http.MaxBytesReader(writer, request.Body, maxImportSize))
+	if err != nil {
+		WriteError(writer, errors.ErrRequestTooLarge)
+		return
+	}
+
+	vevents, err := ical.Decode(bytes.NewReader(data))
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	results := make([]*objects.ItemResult, len(vevents))
+
+	// Import the events before the overrides of their occurrences, which may come first.
+	for i, vevent := range vevents {
+		if vevent.RecurrenceID.IsZero() {
+			results[i] = h.importEvent(request.Context(), i, vevent)
+		}
+	}
+
+	for i, vevent := range vevents {
+		if !vevent.RecurrenceID.IsZero() {
+			results[i] = h.importOccurrence(request.Context(), i, vevent)
+		}
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Results: results})
+}
+
+// importEvent creates or updates the Event of a VEVENT in a transaction, so the item is applied
+// whole or not at all.
+func (h handler) importEvent(ctx context.Context, index int, vevent *ical.VEvent) *objects.ItemResult {
+	result := &objects.ItemResult{Index: index, UID: vevent.UID}
+
+	if err := checkVEvent(vevent); err != nil {
+		return failed(result, err)
+	}
+
+	if err := checkRecurrence(vevent.Event); err != nil {
+		return failed(result, err)
+	}
+
+	imported := *vevent.Event
+
+	err := h.store.WithTx(ctx, func(tx store.EventStore) error {
+		return applyEvent(ctx, tx, result, &imported)
+	})
+
+	// A concurrent import created the Event with the UID first, so update it instead.
+	if err == errors.ErrEventUIDExists {
+		result = &objects.ItemResult{Index: index, UID: vevent.UID}
+		imported = *vevent.Event
+
+		err = h.store.WithTx(ctx, func(tx store.EventStore) error {
+			return applyEvent(ctx, tx, result, &imported)
+		})
+	}
+
+	if err != nil {
+		// The Event wasn't created after all.
+		if result.Status == objects.ItemCreated {
+			result.ID = ""
+		}
+
+		return failed(result, err)
+	}
+
+	return result
+}
+
+// applyEvent creates the imported Event, or updates the Event with its UID.
+func applyEvent(ctx context.Context, st store.EventStore, result *objects.ItemResult, imported *objects.Event) error {
+	existing, err := findImported(ctx, st, result.UID)
+	if err == errors.ErrEventNotFound {
+		canceled := imported.Status == objects.Canceled
+		imported.UID = result.UID
+
+		if err := st.Create(ctx, objects.CreateRequest{Event: imported}); err != nil {
+			return err
+		}
+
+		result.ID = imported.ID
+		result.Succeed(objects.ItemCreated)
+
+		// Events are created as original, so cancel them afterwards.
+		if canceled {
+			return st.Cancel(ctx, objects.CancelRequest{ID: imported.ID})
+		}
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result.ID = existing.ID
+	result.Succeed(objects.ItemSkipped)
+
+	// Recurrence rules are only imported when the Event is created.
+	patch := objects.PatchRequest{
+		ID:          existing.ID,
+		Name:        patchString(imported.Name),
+		Description: patchString(imported.Description),
+		Website:     patchString(imported.Website),
+		Address:     patchString(imported.Address),
+	}
+	patch.Apply(existing)
+
+	if !patch.Empty() {
+		if err := st.Patch(ctx, patch); err != nil {
+			return err
+		}
+
+		result.Succeed(objects.ItemUpdated)
+	}
+
+	if !sameSlot(existing.TimeSlot, imported.TimeSlot) {
+		if err := st.Reschedule(ctx, objects.RescheduleRequest{ID: existing.ID, NewTimeSlot: imported.TimeSlot}); err != nil {
+			return err
+		}
+
+		result.Succeed(objects.ItemUpdated)
+	}
+
+	if imported.Status == objects.Canceled && existing.Status != objects.Canceled {
+		if err := st.Cancel(ctx, objects.CancelRequest{ID: existing.ID}); err != nil {
+			return err
+		}
+
+		result.Succeed(objects.ItemUpdated)
+	}
+
+	return nil
+}
+
+// importOccurrence applies a VEVENT that overrides one occurrence of an imported recurring Event,
+// in a transaction like importEvent.
+func (h handler) importOccurrence(ctx context.Context, index int, vevent *ical.VEvent) *objects.ItemResult {
+	recurrenceID := vevent.RecurrenceID
+	result := &objects.ItemResult{Index: index, UID: vevent.UID, RecurrenceID: &recurrenceID}
+
+	// Overrides inherit the name of the Event they override.
+	if vevent.Err == errors.ErrEventNameIsRequired {
+		vevent.Err = nil
+	}
+
+	if err := checkVEvent(vevent); err != nil {
+		return failed(result, err)
+	}
+
+	err := h.store.WithTx(ctx, func(tx store.EventStore) error {
+		return applyOccurrence(ctx, tx, result, vevent.Event)
+	})
+	if err != nil {
+		return failed(result, err)
+	}
+
+	return result
+}
+
+// applyOccurrence cancels or reschedules the occurrence of the result's RecurrenceID as imported.
+func applyOccurrence(ctx context.Context, st store.EventStore, result *objects.ItemResult, imported *objects.Event) error {
+	recurrenceID := *result.RecurrenceID
+
+	event, err := findImported(ctx, st, result.UID)
+	if err != nil {
+		return err
+	}
+
+	result.ID = event.ID
+	result.Succeed(objects.ItemSkipped)
+
+	overrides, err := st.ListOverrides(ctx, objects.OverridesRequest{IDs: []string{event.ID}})
+	if err != nil {
+		return err
+	}
+
+	// The occurrence as it stands, either overridden or as the recurrence generates it.
+	current := &objects.Occurrence{Status: objects.Original}
+	if event.TimeSlot != nil {
+		current.TimeSlot = &objects.TimeSlot{Start: recurrenceID, End: recurrenceID.Add(event.TimeSlot.End.Sub(event.TimeSlot.Start))}
+	}
+
+	for _, override := range overrides {
+		if override.RecurrenceID.Equal(recurrenceID) {
+			current = override
+		}
+	}
+
+	switch {
+	case imported.Status == objects.Canceled && current.Status != objects.Canceled:
+		err = st.CancelOccurrence(ctx, objects.CancelOccurrenceRequest{ID: event.ID, RecurrenceID: recurrenceID})
+	case imported.Status != objects.Canceled && !sameSlot(current.TimeSlot, imported.TimeSlot):
+		err = st.RescheduleOccurrence(ctx, objects.RescheduleOccurrenceRequest{
+			ID:           event.ID,
+			RecurrenceID: recurrenceID,
+			NewTimeSlot:  imported.TimeSlot,
+		})
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result.Succeed(objects.ItemUpdated)
+
+	return nil
+}
+
+// findImported returns the Event with the UID, which may be the UID of an exported Event.
+func findImported(ctx context.Context, st store.EventStore, uid string) (*objects.Event, error) {
+	if id, ok := ical.EventID(uid); ok {
+		event, err := st.Get(ctx, objects.GetRequest{ID: id})
+		if err != errors.ErrEventNotFound {
+			return event, err
+		}
+	}
+
+	return st.Get(ctx, objects.GetRequest{UID: uid})
+}
+
+// checkVEvent returns why a VEVENT can't be imported.
+func checkVEvent(vevent *ical.VEvent) error {
+	if vevent.Err != nil {
+		return vevent.Err
+	}
+
+	if vevent.UID == "" {
+		return errors.ErrEventUIDIsRequired
+	}
+
+	if err := checkSlot(vevent.Event.TimeSlot); err != nil {
+		return err
+	}
+
+	if !vevent.Event.TimeSlot.Start.Before(vevent.Event.TimeSlot.End) {
+		return errors.ErrInvalidTimeRange
+	}
+
+	return nil
+}
+
+// failed marks the result as failed with the reason from the errors package.
+func failed(result *objects.ItemResult, err error) *objects.ItemResult {
+	result.Fail(err)
+
+	return result
+}
+
+func patchString(value string) objects.PatchString {
+	return objects.PatchString{Set: true, Value: &value}
+}
+
+// sameSlot compares time slots to the second, the precision of iCalendar.
+func sameSlot(a, b *objects.TimeSlot) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Start.Truncate(time.Second).Equal(b.Start.Truncate(time.Second)) &&
+		a.End.Truncate(time.Second).Equal(b.End.Truncate(time.Second))
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+func (h handler) Publish(writer http.ResponseWriter, request *http.Request) {
+	h.transition(writer, request, objects.Published)
+}
+
+func (h handler) Postpone(writer http.ResponseWriter, request *http.Request) {
+	h.transition(writer, request, objects.Postponed)
+}
+
+func (h handler) Complete(writer http.ResponseWriter, request *http.Request) {
+	h.transition(writer, request, objects.Completed)
+}
+
+// Uncancel restores a canceled Event to its status before it was canceled.
+func (h handler) Uncancel(writer http.ResponseWriter, request *http.Request) {
+	id := EventID(request)
+	if id == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	event, err := h.change(request.Context(), id, precondition, func(tx store.EventStore, _ *objects.Event, version int) error {
+		return tx.Uncancel(request.Context(), objects.UncancelRequest{ID: id, Version: version})
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+// transition changes the status of the Event, which the store only allows from certain statuses.
+func (h handler) transition(writer http.ResponseWriter, request *http.Request, status objects.EventStatus) {
+	id := EventID(request)
+	if id == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	event, err := h.change(request.Context(), id, precondition, func(tx store.EventStore, _ *objects.Event, version int) error {
+		return tx.Transition(request.Context(), objects.TransitionRequest{ID: id, Status: status, Version: version})
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/stream"
+)
+
+// Limits of WebSocket connections.
+const (
+	defaultPingInterval = 30 * time.Second
+	writeWait           = 10 * time.Second // for each write, to streams too
+	socketReadLimit     = 4096
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+
+	// The API doesn't use cookies, so pages of any origin may connect, as with its other routes.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// socketFilter is what a subscription of a WebSocket client is for.
+type socketFilter struct {
+	eventID string
+	list    objects.ListRequest
+}
+
+func (f socketFilter) matches(message *stream.Message) bool {
+	if f.eventID != "" {
+		return message.Event.ID == f.eventID
+	}
+
+	return message.Matches(f.list)
+}
+
+// Socket upgrades the request to a WebSocket on which the client subscribes to the changes of
+// Events with SocketRequests. The client must answer pings, and is disconnected when it falls
+// too far behind on the changes, after which it subscribes again.
+func (h handler) Socket(writer http.ResponseWriter, request *http.Request) {
+	conn, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		// The upgrader has written the error.
+		return
+	}
+	defer conn.Close()
+
+	pingInterval := h.pingInterval()
+	pongWait := 2 * pingInterval
+
+	subscription, _, _ := h.broker.Subscribe(0)
+	defer subscription.Close()
+
+	requests := make(chan *objects.SocketRequest)
+	done, closing := make(chan struct{}), make(chan struct{})
+	defer close(closing)
+
+	go readSocket(conn, pongWait, requests, done, closing)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	filters := map[string]socketFilter{}
+
+	write := func(message *objects.SocketMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+		if err := conn.WriteJSON(message); err != nil {
+			log.Println("Unable to write to the WebSocket:", err)
+			return false
+		}
+
+		return true
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case socketRequest := <-requests:
+			if !write(subscribe(filters, socketRequest)) {
+				return
+			}
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				closeMessage := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind")
+				_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(writeWait))
+
+				return
+			}
+
+			var matched []string
+			for name, filter := range filters {
+				if filter.matches(message) {
+					matched = append(matched, name)
+				}
+			}
+
+			if len(matched) == 0 {
+				continue
+			}
+
+			sort.Strings(matched)
+
+			change := &objects.SocketMessage{Type: message.Type, ID: message.ID, Subscriptions: matched, Event: message.Event}
+			if !write(change) {
+				return
+			}
+		}
+	}
+}
+
+func (h handler) pingInterval() time.Duration {
+	if h.options.PingInterval == 0 {
+		return defaultPingInterval
+	}
+
+	return h.options.PingInterval
+}
+
+// readSocket passes the requests of the client on until the connection fails or closes, the
+// client stops answering pings, or closing is closed.
+func readSocket(conn *websocket.Conn, pongWait time.Duration, requests chan<- *objects.SocketRequest, done chan<- struct{}, closing <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(socketReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Println("Unable to read from the WebSocket:", err)
+			}
+
+			return
+		}
+
+		// Requests that aren't JSON are answered as invalid.
+		socketRequest := &objects.SocketRequest{}
+		_ = json.Unmarshal(data, socketRequest)
+
+		select {
+		case requests <- socketRequest:
+		case <-closing:
+			return
+		}
+	}
+}
+
+// subscribe applies the request to the filters of the client's subscriptions and returns the
+// answer.
+func subscribe(filters map[string]socketFilter, request *objects.SocketRequest) *objects.SocketMessage {
+	fail := func(err *errors.Error) *objects.SocketMessage {
+		return &objects.SocketMessage{Type: objects.SocketError, Subscription: request.Subscription, Error: err}
+	}
+
+	if request.Type != objects.SocketSubscribe && request.Type != objects.SocketUnsubscribe {
+		return fail(errors.ErrInvalidSocketRequest)
+	}
+
+	if request.Subscription == "" {
+		return fail(errors.ErrSubscriptionIsRequired)
+	}
+
+	if request.Type == objects.SocketUnsubscribe {
+		if _, ok := filters[request.Subscription]; !ok {
+			return fail(errors.ErrSubscriptionNotFound)
+		}
+
+		delete(filters, request.Subscription)
+
+		return &objects.SocketMessage{Type: objects.SocketUnsubscribed, Subscription: request.Subscription}
+	}
+
+	if _, ok := filters[request.Subscription]; ok {
+		return fail(errors.ErrSubscriptionExists)
+	}
+
+	filter := socketFilter{eventID: request.EventID}
+
+	if request.EventID == "" {
+		values, err := url.ParseQuery(strings.TrimPrefix(request.Filter, "?"))
+		if err != nil {
+			return fail(errors.ErrBadRequest)
+		}
+
+		if filter.list, err = ParseListQuery(values); err != nil {
+			return fail(err.(*errors.Error))
+		}
+	} else if request.Filter != "" {
+		return fail(errors.ErrBadRequest)
+	}
+
+	filters[request.Subscription] = filter
+
+	return &objects.SocketMessage{Type: objects.SocketSubscribed, Subscription: request.Subscription}
+}
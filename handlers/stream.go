@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/stream"
+)
+
+// Stream sends the changes of the Events matching the GET /events filters as Server-Sent Events,
+// resuming after the Last-Event-ID header or query parameter when given.
+func (h handler) Stream(writer http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+
+	listRequest, err := ListRequestFromQuery(writer, values)
+	if err != nil {
+		return
+	}
+
+	lastEventID := request.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = values.Get("last-event-id")
+	}
+
+	var lastID int64
+	if lastEventID != "" {
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			WriteError(writer, errors.ErrInvalidCursor)
+			return
+		}
+	}
+
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		WriteError(writer, errors.ErrInternal)
+		return
+	}
+
+	subscription, replay, resumed := h.broker.Subscribe(lastID)
+	defer subscription.Close()
+
+	// Streams outlast the server's WriteTimeout, so each write gets its own deadline instead.
+	setWriteDeadline(request, time.Now().Add(writeWait))
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+
+	// The changes since the Last-Event-ID were missed, so the client has to list the Events again.
+	if !resumed {
+		_, _ = fmt.Fprint(writer, "event: reset\ndata: null\n\n")
+	}
+
+	for _, message := range replay {
+		if message.Matches(listRequest) {
+			writeMessage(writer, message)
+		}
+	}
+
+	flusher.Flush()
+
+	// Keep-alive comments stop proxies from closing the idle stream, and find clients that are gone.
+	ticker := time.NewTicker(h.pingInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case <-ticker.C:
+			setWriteDeadline(request, time.Now().Add(writeWait))
+
+			_, _ = fmt.Fprint(writer, ": keep-alive\n\n")
+			flusher.Flush()
+		case message, ok := <-subscription.Messages():
+			// The client fell too far behind and resumes from the buffer when it reconnects.
+			if !ok {
+				return
+			}
+
+			if message.Matches(listRequest) {
+				setWriteDeadline(request, time.Now().Add(writeWait))
+
+				writeMessage(writer, message)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+type connKey struct{}
+
+// ConnContext is the ConnContext of the http.Server, which puts the connection on the context of
+// its requests so streams can replace the server's WriteTimeout with their own deadlines.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// setWriteDeadline sets the write deadline of the request's connection when it's known. The
+// server sets it again for the next request on the connection.
+func setWriteDeadline(request *http.Request, deadline time.Time) {
+	if conn, ok := request.Context().Value(connKey{}).(net.Conn); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+}
+
+func writeMessage(writer http.ResponseWriter, message *stream.Message) {
+	data, _ := json.Marshal(message.Event)
+
+	_, _ = fmt.Fprintf(writer, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Type, data)
+}
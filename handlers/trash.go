@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// Restore brings a deleted Event back from the trash.
+func (h handler) Restore(writer http.ResponseWriter, request *http.Request) {
+	id := EventID(request)
+	if id == "" {
+		WriteError(writer, errors.ErrValidEventIDIsRequired)
+		return
+	}
+
+	precondition, err := IfMatch(request, h.options.RequireIfMatch)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	var event *objects.Event
+
+	err = h.store.WithTx(request.Context(), func(tx store.EventStore) error {
+		deleted, err := tx.Get(request.Context(), objects.GetRequest{ID: id, Deleted: true})
+		if err != nil {
+			return err
+		}
+
+		version, err := precondition.Check(deleted)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Restore(request.Context(), objects.RestoreRequest{ID: id, Version: version}); err != nil {
+			return err
+		}
+
+		event, err = tx.Get(request.Context(), objects.GetRequest{ID: id})
+
+		return err
+	})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	writer.Header().Set("ETag", ETag(event.Version))
+
+	WriteResponse(writer, &objects.EventResponse{Event: event})
+}
+
+// Trash lists the deleted Events that haven't been purged yet, with the same filters as List.
+func (h handler) Trash(writer http.ResponseWriter, request *http.Request) {
+	listRequest, err := ListRequestFromQuery(writer, request.URL.Query())
+	if err != nil {
+		return
+	}
+
+	listRequest.Deleted = true
+
+	events, meta, err := h.store.List(request.Context(), listRequest)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Events: events, Meta: meta, NextCursor: meta.NextCursor})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+// CreateWebhook subscribes a Webhook to the changes of Events. Its secret is only in this response.
+func (h handler) CreateWebhook(writer http.ResponseWriter, request *http.Request) {
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	webhook := &objects.Webhook{}
+	if UnmarshalStrict(writer, data, webhook) != nil {
+		return
+	}
+
+	if err := checkWebhook(webhook.URL, webhook.Operations); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	webhook.ID, webhook.Secret = "", ""
+	webhook.CreatedAt, webhook.UpdatedAt = time.Time{}, time.Time{}
+
+	if err := h.store.CreateWebhook(request.Context(), objects.CreateWebhookRequest{Webhook: webhook}); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Webhook: webhook})
+}
+
+func (h handler) ListWebhooks(writer http.ResponseWriter, request *http.Request) {
+	webhooks, err := h.store.ListWebhooks(request.Context())
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Webhooks: webhooks})
+}
+
+func (h handler) GetWebhook(writer http.ResponseWriter, request *http.Request) {
+	webhook, err := h.store.GetWebhook(request.Context(), objects.GetWebhookRequest{ID: mux.Vars(request)["id"]})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	webhook.Secret = ""
+
+	WriteResponse(writer, &objects.EventResponse{Webhook: webhook})
+}
+
+// UpdateWebhook replaces the URL and operations of a Webhook, which keeps its secret.
+func (h handler) UpdateWebhook(writer http.ResponseWriter, request *http.Request) {
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		WriteError(writer, errors.ErrUnprocessableEntity)
+		return
+	}
+
+	updateRequest := &objects.UpdateWebhookRequest{}
+	if UnmarshalStrict(writer, data, updateRequest) != nil {
+		return
+	}
+
+	if err := checkWebhook(updateRequest.URL, updateRequest.Operations); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	updateRequest.ID = mux.Vars(request)["id"]
+
+	if err := h.store.UpdateWebhook(request.Context(), *updateRequest); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	h.GetWebhook(writer, request)
+}
+
+func (h handler) DeleteWebhook(writer http.ResponseWriter, request *http.Request) {
+	err := h.store.DeleteWebhook(request.Context(), objects.DeleteWebhookRequest{ID: mux.Vars(request)["id"]})
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+// Deliveries lists the latest deliveries to a Webhook, optionally only those with a status such
+// as dead.
+func (h handler) Deliveries(writer http.ResponseWriter, request *http.Request) {
+	values := request.URL.Query()
+	listRequest := objects.ListDeliveriesRequest{
+		WebhookID: mux.Vars(request)["id"],
+		Status:    objects.DeliveryStatus(values.Get("status")),
+	}
+
+	switch listRequest.Status {
+	case "", objects.DeliveryPending, objects.DeliveryDelivered, objects.DeliveryDead:
+	default:
+		WriteError(writer, errors.ErrInvalidStatus)
+		return
+	}
+
+	var err error
+	if listRequest.Limit, err = IntFromString(writer, values.Get("limit")); err != nil {
+		return
+	}
+
+	deliveries, err := h.store.ListDeliveries(request.Context(), listRequest)
+	if err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{Deliveries: deliveries})
+}
+
+// RetryDelivery attempts a dead delivery again, as if it were new.
+func (h handler) RetryDelivery(writer http.ResponseWriter, request *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(request)["delivery-id"], 10, 64)
+	if err != nil {
+		WriteError(writer, errors.ErrDeliveryNotFound)
+		return
+	}
+
+	retryRequest := objects.RetryDeliveryRequest{WebhookID: mux.Vars(request)["id"], ID: id}
+	if err := h.store.RetryDelivery(request.Context(), retryRequest); err != nil {
+		WriteError(writer, err)
+		return
+	}
+
+	WriteResponse(writer, &objects.EventResponse{})
+}
+
+func checkWebhook(rawURL string, operations objects.OperationList) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.ErrInvalidWebhookURL
+	}
+
+	for _, operation := range operations {
+		if !operation.Valid() {
+			return errors.ErrInvalidOperation
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+// dateFormat is the RFC 5545 DATE format of all-day events.
+const dateFormat = "20060102"
+
+// VEvent is a VEVENT read by Decode.
+type VEvent struct {
+	UID string
+
+	// RecurrenceID is set when the VEVENT overrides one occurrence of a recurring event.
+	RecurrenceID time.Time
+
+	// Event holds the VEVENT's fields, Err why they can't be imported.
+	Event *objects.Event
+	Err   error
+}
+
+// property is a content line of a component.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode reads the VEVENTs of a VCALENDAR. Nested components such as VALARMs and other
+// components such as VTODOs are ignored. A VEVENT that can't be mapped to an Event is
+// returned with its Err set; Decode only fails when the calendar itself is malformed.
+func Decode(r io.Reader) ([]*VEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []*VEvent
+		stack    []string
+		props    []property
+		calendar bool
+	)
+
+	for _, line := range lines {
+		prop, ok := parseLine(line)
+		if !ok {
+			return nil, errors.ErrInvalidCalendar
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(prop.value))
+			if len(stack) == 1 {
+				if stack[0] != "VCALENDAR" {
+					return nil, errors.ErrInvalidCalendar
+				}
+
+				calendar = true
+			}
+
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				props = nil
+			}
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.value) {
+				return nil, errors.ErrInvalidCalendar
+			}
+
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				events = append(events, vevent(props))
+			}
+
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				props = append(props, prop)
+			}
+		}
+	}
+
+	if !calendar || len(stack) != 0 {
+		return nil, errors.ErrInvalidCalendar
+	}
+
+	return events, nil
+}
+
+// unfold joins folded content lines, accepting bare LF line endings too.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if len(lines) == 0 {
+				return nil, errors.ErrInvalidCalendar
+			}
+
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.ErrInvalidCalendar
+	}
+
+	return lines, nil
+}
+
+// parseLine splits a content line into its name, parameters and value.
+func parseLine(line string) (property, bool) {
+	prop := property{params: map[string]string{}}
+
+	// The value starts at the first colon that isn't inside a quoted parameter value.
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+
+	if colon <= 0 {
+		return prop, false
+	}
+
+	prop.value = line[colon+1:]
+
+	parts := splitParams(line[:colon])
+	prop.name = strings.ToUpper(parts[0])
+
+	for _, param := range parts[1:] {
+		eq := strings.Index(param, "=")
+		if eq <= 0 {
+			return prop, false
+		}
+
+		prop.params[strings.ToUpper(param[:eq])] = strings.Trim(param[eq+1:], `"`)
+	}
+
+	return prop, true
+}
+
+// splitParams splits a property name from its parameters on semicolons outside quotes.
+func splitParams(s string) []string {
+	var parts []string
+
+	quoted := false
+	start := 0
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ';' && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// vevent maps the properties of a VEVENT onto an Event.
+func vevent(props []property) *VEvent {
+	v := &VEvent{Event: &objects.Event{Status: objects.Original}}
+
+	var (
+		start, end time.Time
+		duration   time.Duration
+		allDay     bool
+	)
+
+	for _, prop := range props {
+		var err error
+
+		switch prop.name {
+		case "UID":
+			v.UID = prop.value
+		case "SUMMARY":
+			v.Event.Name = Unescape(prop.value)
+		case "DESCRIPTION":
+			v.Event.Description = Unescape(prop.value)
+		case "LOCATION":
+			v.Event.Address = Unescape(prop.value)
+		case "URL":
+			v.Event.Website = prop.value
+		case "STATUS":
+			if strings.EqualFold(prop.value, "CANCELLED") {
+				v.Event.Status = objects.Canceled
+			}
+		case "DTSTART":
+			start, err = parseTime(prop)
+			allDay = prop.params["VALUE"] == "DATE" || len(prop.value) == len(dateFormat)
+		case "DTEND":
+			end, err = parseTime(prop)
+		case "DURATION":
+			duration, err = parseDuration(prop.value)
+		case "RECURRENCE-ID":
+			v.RecurrenceID, err = parseTime(prop)
+		case "RRULE":
+			v.Event.RRule = prop.value
+		case "EXDATE":
+			v.Event.ExDates, err = appendTimes(v.Event.ExDates, prop)
+		case "RDATE":
+			v.Event.RDates, err = appendTimes(v.Event.RDates, prop)
+		}
+
+		if err != nil && v.Err == nil {
+			v.Err = err
+		}
+	}
+
+	if start.IsZero() {
+		v.setErr(errors.ErrEventTimingIsRequired)
+		return v
+	}
+
+	switch {
+	case !end.IsZero():
+	case duration > 0:
+		end = start.Add(duration)
+	case allDay:
+		end = start.AddDate(0, 0, 1)
+	default:
+		v.setErr(errors.ErrEventTimingIsRequired)
+		return v
+	}
+
+	v.Event.TimeSlot = &objects.TimeSlot{Start: start, End: end}
+
+	if v.Event.Name == "" {
+		v.setErr(errors.ErrEventNameIsRequired)
+	}
+
+	return v
+}
+
+func (v *VEvent) setErr(err error) {
+	if v.Err == nil {
+		v.Err = err
+	}
+}
+
+// parseTime parses a DATE-TIME, or a DATE for all-day events, in UTC, in its TZID or floating.
+// Floating times are read as UTC.
+func parseTime(prop property) (time.Time, error) {
+	loc := time.UTC
+
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, errors.ErrInvalidTimeFormat
+		}
+	}
+
+	value := prop.value
+	layout := "20060102T150405"
+
+	switch {
+	case len(value) == len(dateFormat):
+		layout = dateFormat
+	case strings.HasSuffix(value, "Z"):
+		value = strings.TrimSuffix(value, "Z")
+		loc = time.UTC
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, errors.ErrInvalidTimeFormat
+	}
+
+	return t.UTC(), nil
+}
+
+// appendTimes appends the comma separated times of an EXDATE or RDATE.
+func appendTimes(list objects.TimeList, prop property) (objects.TimeList, error) {
+	for _, value := range strings.Split(prop.value, ",") {
+		t, err := parseTime(property{name: prop.name, params: prop.params, value: value})
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, t)
+	}
+
+	return list, nil
+}
+
+// parseDuration parses a positive RFC 5545 DURATION such as P1D, PT1H30M or P2W.
+func parseDuration(value string) (time.Duration, error) {
+	if !strings.HasPrefix(value, "P") && !strings.HasPrefix(value, "+P") {
+		return 0, errors.ErrEventTimingIsRequired
+	}
+
+	value = value[strings.Index(value, "P")+1:]
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var d time.Duration
+
+	number := ""
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+		case units[c] != 0 && number != "":
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, errors.ErrEventTimingIsRequired
+			}
+
+			d += time.Duration(n) * units[c]
+			number = ""
+		default:
+			return 0, errors.ErrEventTimingIsRequired
+		}
+	}
+
+	if number != "" {
+		return 0, errors.ErrEventTimingIsRequired
+	}
+
+	return d, nil
+}
+
+// Unescape reverses Escape on a TEXT value.
+func Unescape(value string) string {
+	var b strings.Builder
+
+	escaped := false
+	for _, r := range value {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+			} else {
+				b.WriteRune(r)
+			}
+
+			continue
+		}
+
+		escaped = false
+
+		if r == 'n' || r == 'N' {
+			b.WriteRune('\n')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
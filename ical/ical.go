@@ -0,0 +1,236 @@
+// Package ical renders Events as RFC 5545 iCalendar data.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/theantichris/events-api/objects"
+)
+
+// ContentType is the media type of iCalendar data.
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets is the longest a content line may be before it's folded.
+const maxLineOctets = 75
+
+// dateTimeFormat is the RFC 5545 UTC date-time format.
+const dateTimeFormat = "20060102T150405Z"
+
+// uidDomain makes Event IDs globally unique UIDs.
+const uidDomain = "@events-api"
+
+// Encode writes the events as a VCALENDAR. Overrides of occurrences of recurring events are
+// written as their own VEVENTs with a RECURRENCE-ID.
+func Encode(w io.Writer, events []*objects.Event, overrides []*objects.Occurrence) error {
+	enc := &encoder{w: bufio.NewWriter(w)}
+
+	enc.line("BEGIN", "VCALENDAR")
+	enc.line("VERSION", "2.0")
+	enc.line("PRODID", "-//theantichris//events-api//EN")
+	enc.line("CALSCALE", "GREGORIAN")
+	enc.line("METHOD", "PUBLISH")
+
+	byEvent := map[string][]*objects.Occurrence{}
+	for _, override := range overrides {
+		byEvent[override.EventID] = append(byEvent[override.EventID], override)
+	}
+
+	for _, event := range events {
+		enc.event(event)
+
+		for _, override := range byEvent[event.ID] {
+			enc.override(event, override)
+		}
+	}
+
+	enc.line("END", "VCALENDAR")
+
+	if enc.err != nil {
+		return enc.err
+	}
+
+	return enc.w.Flush()
+}
+
+// UID returns the iCalendar UID of an Event, keeping the UID of imported Events.
+func UID(event *objects.Event) string {
+	if event.UID != "" {
+		return event.UID
+	}
+
+	return event.ID + uidDomain
+}
+
+// EventID returns the Event ID of a UID that was exported by UID.
+func EventID(uid string) (string, bool) {
+	if !strings.HasSuffix(uid, uidDomain) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(uid, uidDomain), true
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (enc *encoder) event(event *objects.Event) {
+	enc.line("BEGIN", "VEVENT")
+	enc.line("UID", UID(event))
+	enc.dateTime("DTSTAMP", stamp(event))
+	enc.dateTime("CREATED", event.CreatedAt)
+	enc.dateTime("LAST-MODIFIED", event.UpdatedAt)
+	enc.line("SEQUENCE", sequence(event))
+
+	if event.TimeSlot != nil {
+		enc.dateTime("DTSTART", event.TimeSlot.Start)
+		enc.dateTime("DTEND", event.TimeSlot.End)
+	}
+
+	if event.RRule != "" {
+		enc.line("RRULE", event.RRule)
+	}
+
+	for _, t := range event.ExDates {
+		enc.dateTime("EXDATE", t)
+	}
+
+	for _, t := range event.RDates {
+		enc.dateTime("RDATE", t)
+	}
+
+	enc.details(event)
+	enc.line("STATUS", status(event.Status))
+	enc.line("END", "VEVENT")
+}
+
+func (enc *encoder) override(event *objects.Event, override *objects.Occurrence) {
+	enc.line("BEGIN", "VEVENT")
+	enc.line("UID", UID(event))
+	enc.dateTime("DTSTAMP", stamp(event))
+	enc.dateTime("RECURRENCE-ID", override.RecurrenceID)
+	enc.line("SEQUENCE", sequence(event))
+
+	if override.TimeSlot != nil {
+		enc.dateTime("DTSTART", override.TimeSlot.Start)
+		enc.dateTime("DTEND", override.TimeSlot.End)
+	}
+
+	enc.details(event)
+	enc.line("STATUS", status(override.Status))
+	enc.line("END", "VEVENT")
+}
+
+func (enc *encoder) details(event *objects.Event) {
+	enc.text("SUMMARY", event.Name)
+	enc.text("DESCRIPTION", event.Description)
+	enc.text("LOCATION", event.Address)
+
+	if event.Website != "" {
+		enc.line("URL", event.Website)
+	}
+}
+
+func (enc *encoder) dateTime(name string, t time.Time) {
+	if !t.IsZero() {
+		enc.line(name, t.UTC().Format(dateTimeFormat))
+	}
+}
+
+func (enc *encoder) text(name, value string) {
+	if value != "" {
+		enc.line(name, Escape(value))
+	}
+}
+
+// line writes a content line, folding it so no line is longer than 75 octets. Control characters
+// other than tabs aren't allowed in values, and would let a value such as a URL break out of its line.
+func (enc *encoder) line(name, value string) {
+	if enc.err != nil {
+		return
+	}
+
+	value = strings.Map(func(r rune) rune {
+		if r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, value)
+
+	_, enc.err = enc.w.WriteString(Fold(name+":"+value) + "\r\n")
+}
+
+// Escape escapes a TEXT value.
+func Escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+// Fold splits a content line into lines of at most 75 octets, continued with a leading space.
+// Lines are only split between UTF-8 characters.
+func Fold(line string) string {
+	var b strings.Builder
+
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+
+	b.WriteString(line)
+
+	return b.String()
+}
+
+// stamp is when the Event's iCalendar data last changed.
+func stamp(event *objects.Event) time.Time {
+	if !event.UpdatedAt.IsZero() {
+		return event.UpdatedAt
+	}
+
+	return event.CreatedAt
+}
+
+// sequence increases with every version of the Event, so rescheduling bumps it.
+func sequence(event *objects.Event) string {
+	if event.Version <= 1 {
+		return "0"
+	}
+
+	return strconv.Itoa(event.Version - 1)
+}
+
+func status(status objects.EventStatus) string {
+	switch status {
+	case objects.Canceled:
+		return "CANCELLED"
+	case objects.Draft, objects.Postponed:
+		return "TENTATIVE"
+	}
+
+	return "CONFIRMED"
+}
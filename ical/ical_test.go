@@ -0,0 +1,162 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b\; c\, d\ne`, Escape("a\\b; c, d\r\ne"))
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "Short", line: "SUMMARY:Short"},
+		{name: "ASCII", line: "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{name: "MultiByte", line: "DESCRIPTION:" + strings.Repeat("é", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := Fold(tt.line)
+
+			for _, line := range strings.Split(folded, "\r\n") {
+				assert.LessOrEqual(t, len(line), maxLineOctets)
+				assert.True(t, utf8Valid(line))
+			}
+
+			assert.Equal(t, tt.line, strings.ReplaceAll(folded, "\r\n ", ""))
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC)
+	event := &objects.Event{
+		ID:       "1",
+		Name:     "Meetup, weekly",
+		Status:   objects.Canceled,
+		Version:  3,
+		RRule:    "FREQ=WEEKLY;COUNT=4",
+		TimeSlot: &objects.TimeSlot{Start: start, End: start.Add(time.Hour)},
+	}
+	override := &objects.Occurrence{
+		EventID:      "1",
+		RecurrenceID: start,
+		Status:       objects.Rescheduled,
+		TimeSlot:     &objects.TimeSlot{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Encode(buf, []*objects.Event{event}, []*objects.Occurrence{override}))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "UID:1@events-api\r\n")
+	assert.Contains(t, out, `SUMMARY:Meetup\, weekly`+"\r\n")
+	assert.Contains(t, out, "STATUS:CANCELLED\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+	assert.Contains(t, out, "RECURRENCE-ID:20300101T180000Z\r\nSEQUENCE:2\r\nDTSTART:20300101T190000Z\r\n")
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+}
+
+func TestEncodeControlCharacters(t *testing.T) {
+	start := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC)
+	event := &objects.Event{
+		ID:       "1",
+		Name:     "Meetup",
+		Website:  "https://example.com/\r\nEND:VEVENT\r\nBEGIN:VEVENT\nSUMMARY:Injected",
+		TimeSlot: &objects.TimeSlot{Start: start, End: start.Add(time.Hour)},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Encode(buf, []*objects.Event{event}, nil))
+
+	out := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, out, "URL:https://example.com/END:VEVENTBEGIN:VEVENTSUMMARY:Injected\r\n")
+	assert.Equal(t, 1, strings.Count(out, "\r\nBEGIN:VEVENT\r\n"))
+	assert.NotContains(t, out, "\r\nSUMMARY:Injected")
+}
+
+func utf8Valid(s string) bool {
+	return strings.ToValidUTF8(s, "�") == s
+}
+
+func TestDecode(t *testing.T) {
+	body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Jazz\\, live\r\nDESCRIPTION:Line one\\nline two that is long enough to be fol\r\n ded\r\n" +
+		"DTSTART;TZID=America/New_York:20300101T180000\r\nDURATION:PT1H30M\r\nSTATUS:CANCELLED\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Ignored\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\nUID:b\nSUMMARY:Holiday\nDTSTART;VALUE=DATE:20300704\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\r\nUID:a\r\nRECURRENCE-ID:20300108T230000Z\r\nDTSTART:20300109T230000Z\r\nDTEND:20300110T000000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:c\r\nSUMMARY:Broken\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:d\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+	vevents, err := Decode(strings.NewReader(body))
+	assert.Nil(t, err)
+	assert.Len(t, vevents, 4)
+
+	jazz := vevents[0]
+	assert.Nil(t, jazz.Err)
+	assert.Equal(t, "a", jazz.UID)
+	assert.Equal(t, "Jazz, live", jazz.Event.Name)
+	assert.Equal(t, "Line one\nline two that is long enough to be folded", jazz.Event.Description)
+	assert.Equal(t, objects.Canceled, jazz.Event.Status)
+	assert.Equal(t, time.Date(2030, 1, 1, 23, 0, 0, 0, time.UTC), jazz.Event.TimeSlot.Start)
+	assert.Equal(t, 90*time.Minute, jazz.Event.TimeSlot.End.Sub(jazz.Event.TimeSlot.Start))
+
+	holiday := vevents[1]
+	assert.Nil(t, holiday.Err)
+	assert.Equal(t, 24*time.Hour, holiday.Event.TimeSlot.End.Sub(holiday.Event.TimeSlot.Start))
+
+	assert.Equal(t, time.Date(2030, 1, 8, 23, 0, 0, 0, time.UTC), vevents[2].RecurrenceID)
+	assert.Equal(t, errors.ErrInvalidTimeFormat, vevents[3].Err)
+
+	for _, invalid := range []string{"", "BEGIN:VEVENT\r\nEND:VEVENT\r\n", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"} {
+		_, err := Decode(strings.NewReader(invalid))
+		assert.Equal(t, errors.ErrInvalidCalendar, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC)
+	event := &objects.Event{
+		ID:          "1",
+		Name:        "Semi; colons, commas and \\ backslashes",
+		Description: strings.Repeat("Long description é ", 20),
+		Address:     "1 Main St\nSpringfield",
+		Website:     "https://example.com",
+		Status:      objects.Original,
+		RRule:       "FREQ=DAILY;COUNT=3",
+		ExDates:     objects.TimeList{start.AddDate(0, 0, 1)},
+		TimeSlot:    &objects.TimeSlot{Start: start, End: start.Add(time.Hour)},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, Encode(buf, []*objects.Event{event}, nil))
+
+	vevents, err := Decode(buf)
+	assert.Nil(t, err)
+	assert.Len(t, vevents, 1)
+	assert.Nil(t, vevents[0].Err)
+	assert.Equal(t, UID(event), vevents[0].UID)
+
+	got := vevents[0].Event
+	assert.Equal(t, event.Name, got.Name)
+	assert.Equal(t, event.Description, got.Description)
+	assert.Equal(t, event.Address, got.Address)
+	assert.Equal(t, event.Website, got.Website)
+	assert.Equal(t, event.RRule, got.RRule)
+	assert.Equal(t, event.ExDates, got.ExDates)
+	assert.Equal(t, event.TimeSlot, got.TimeSlot)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,11 +15,92 @@ func main() {
 	}
 
 	args := Args{
-		conn: os.Getenv("DB"),
-		port: os.Getenv("PORT"),
+		conn:            os.Getenv("DB"),
+		connTimeout:     durationFromEnv("DB_CONNECT_TIMEOUT"),
+		port:            os.Getenv("PORT"),
+		requireIfMatch:  boolFromEnv("REQUIRE_IF_MATCH"),
+		readTimeout:     durationFromEnv("READ_TIMEOUT"),
+		writeTimeout:    durationFromEnv("WRITE_TIMEOUT"),
+		idleTimeout:     durationFromEnv("IDLE_TIMEOUT"),
+		shutdownTimeout: durationFromEnv("SHUTDOWN_TIMEOUT"),
+		purgeRetention:  positiveDurationFromEnv("PURGE_RETENTION"),
+		purgeInterval:   positiveDurationFromEnv("PURGE_INTERVAL"),
+		apiKeys:         os.Getenv("API_KEYS"),
+		jwksFile:        os.Getenv("JWKS_FILE"),
+		jwtIssuer:       os.Getenv("JWT_ISSUER"),
+		jwtAudience:     os.Getenv("JWT_AUDIENCE"),
+		authDisabled:    boolFromEnv("AUTH_DISABLED"),
+		admins:          os.Getenv("ADMINS"),
+
+		webhooksAllowPrivate: boolFromEnv("WEBHOOKS_ALLOW_PRIVATE"),
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		command := ""
+		if len(os.Args) > 2 {
+			command = os.Args[2]
+		}
+
+		if err := Migrate(args, command); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "api-key" {
+		if len(os.Args) != 3 {
+			log.Fatal("Usage: main api-key NAME")
+		}
+
+		if err := NewAPIKey(os.Args[2]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
 	}
 
 	if err := Run(args); err != nil {
-		log.Println(err)
+		log.Fatal(err)
+	}
+}
+
+// durationFromEnv parses a duration e.g. "30s" from the named environment variable, or returns 0 if unset.
+func durationFromEnv(name string) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
 	}
+
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %s", name, err)
+	}
+
+	return duration
+}
+
+// positiveDurationFromEnv is durationFromEnv for durations that must be positive when set.
+func positiveDurationFromEnv(name string) time.Duration {
+	duration := durationFromEnv(name)
+	if os.Getenv(name) != "" && duration <= 0 {
+		log.Fatalf("Invalid %s: must be positive", name)
+	}
+
+	return duration
+}
+
+// boolFromEnv parses a boolean e.g. "true" from the named environment variable, or returns false if unset.
+func boolFromEnv(name string) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("Invalid %s: %s", name, err)
+	}
+
+	return b
 }
@@ -0,0 +1,47 @@
+package objects
+
+// MaxBatchOperations is the most operations a BatchRequest may have.
+const MaxBatchOperations = 1000
+
+// BatchOp is the kind of change made by a BatchOperation.
+type BatchOp string
+
+// Batch operations.
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update" // replaces the Event's details, as UpdateRequest
+	BatchCancel BatchOp = "cancel"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchMode is how a BatchRequest handles failed operations.
+type BatchMode string
+
+// Batch modes.
+const (
+	// BatchAtomic applies all operations in one transaction, or none of them when any fails.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies each operation independently.
+	BatchBestEffort BatchMode = "best-effort"
+)
+
+// BatchOperation is one change of a BatchRequest.
+type BatchOperation struct {
+	Op    BatchOp `json:"op"`
+	ID    string  `json:"id,omitempty"`    // of the Event to update, cancel or delete
+	Event *Event  `json:"event,omitempty"` // to create, or the details to update
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"version,omitempty"`
+}
+
+// BatchRequest is for applying many changes to Events in one request.
+type BatchRequest struct {
+	Mode       BatchMode         `json:"mode"` // BatchAtomic when empty
+	Operations []*BatchOperation `json:"operations"`
+}
+
+// Atomic reports whether the operations are all-or-nothing.
+func (r BatchRequest) Atomic() bool {
+	return r.Mode != BatchBestEffort
+}
@@ -0,0 +1,156 @@
+package objects
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortField is a field that Events can be listed by.
+type SortField string
+
+// Sortable fields, Events are listed by ID by default.
+const (
+	SortByID        SortField = "id"
+	SortByStart     SortField = "start"
+	SortByEnd       SortField = "end"
+	SortByName      SortField = "name"
+	SortByCreatedAt SortField = "created-at"
+	SortByRelevance SortField = "relevance" // only with a full-text search
+)
+
+// sortKeyTimeFormat is fixed width so formatted times order the same as the times.
+const sortKeyTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// Sort is the order to list Events in. Ties are broken by ID in the same direction.
+type Sort struct {
+	Field      SortField `json:"field"`
+	Descending bool      `json:"desc,omitempty"`
+}
+
+// ParseSort parses a sort such as "start" or "-start" for descending, an empty string sorts by ID.
+func ParseSort(v string) (Sort, bool) {
+	sort := Sort{Field: SortByID}
+
+	if strings.HasPrefix(v, "-") {
+		sort.Descending = true
+		v = v[1:]
+	}
+
+	if v == "" {
+		return sort, !sort.Descending
+	}
+
+	sort.Field = SortField(v)
+
+	switch sort.Field {
+	case SortByID, SortByStart, SortByEnd, SortByName, SortByCreatedAt, SortByRelevance:
+		return sort, true
+	}
+
+	return sort, false
+}
+
+// String formats the sort the way ParseSort expects it.
+func (s Sort) String() string {
+	if s.Descending {
+		return "-" + string(s.Field)
+	}
+
+	return string(s.Field)
+}
+
+// IsTime reports whether the sort field holds a time.
+func (s Sort) IsTime() bool {
+	return s.Field == SortByStart || s.Field == SortByEnd || s.Field == SortByCreatedAt
+}
+
+// Key returns the event's value for the sort field, formatted so keys order the same as the values.
+func (s Sort) Key(event *Event) string {
+	slot := event.TimeSlot
+	if slot == nil {
+		slot = &TimeSlot{}
+	}
+
+	switch s.Field {
+	case SortByStart:
+		return slot.Start.UTC().Format(sortKeyTimeFormat)
+	case SortByEnd:
+		return slot.End.UTC().Format(sortKeyTimeFormat)
+	case SortByName:
+		return event.Name
+	case SortByCreatedAt:
+		return event.CreatedAt.UTC().Format(sortKeyTimeFormat)
+	case SortByRelevance:
+		if event.Match == nil {
+			return RankKey(0)
+		}
+
+		return RankKey(event.Match.Rank)
+	default:
+		return event.ID
+	}
+}
+
+// KeyTime parses a time Key back into a time.
+func KeyTime(key string) (time.Time, error) {
+	return time.Parse(sortKeyTimeFormat, key)
+}
+
+// RankKey formats a search rank as a fixed width Key, rounding it to 9 decimals.
+func RankKey(rank float64) string {
+	return fmt.Sprintf("%020.9f", rank)
+}
+
+// Cursor marks a position in a sorted list of Events, listing continues after it.
+type Cursor struct {
+	Sort Sort   `json:"sort"`
+	Key  string `json:"key"`
+	ID   string `json:"id"`
+}
+
+// CursorAfter returns the Cursor positioned after the event in the given sort.
+func CursorAfter(event *Event, sort Sort) *Cursor {
+	return &Cursor{Sort: sort, Key: sort.Key(event), ID: event.ID}
+}
+
+// Encode returns the cursor as an opaque string for clients.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode.
+func DecodeCursor(v string) (*Cursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, false
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID == "" {
+		return nil, false
+	}
+
+	if _, ok := ParseSort(cursor.Sort.String()); !ok {
+		return nil, false
+	}
+
+	if cursor.Sort.IsTime() {
+		if _, err := KeyTime(cursor.Key); err != nil {
+			return nil, false
+		}
+	}
+
+	if cursor.Sort.Field == SortByRelevance {
+		if _, err := strconv.ParseFloat(cursor.Key, 64); err != nil {
+			return nil, false
+		}
+	}
+
+	return cursor, true
+}
@@ -1,17 +1,34 @@
 package objects
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
 
 // EventStatus holds the status of the event.
 type EventStatus string
 
-// Default event statuses.
+// Event statuses, see transitions for how an Event moves between them.
 const (
-	Original    EventStatus = "original"
-	Canceled    EventStatus = "canceled"
+	Draft       EventStatus = "draft"
+	Original    EventStatus = "original" // created without being drafted first
+	Published   EventStatus = "published"
 	Rescheduled EventStatus = "rescheduled"
+	Postponed   EventStatus = "postponed" // put off until it is rescheduled
+	Canceled    EventStatus = "canceled"
+	Completed   EventStatus = "completed"
 )
 
+// Valid reports whether the status is one of the known EventStatus values.
+func (s EventStatus) Valid() bool {
+	_, ok := transitions[s]
+
+	return ok
+}
+
 // TimeSlot holds the start and end times for the event.
 type TimeSlot struct {
 	Start time.Time `json:"start,omitempty"`
@@ -22,6 +39,9 @@ type TimeSlot struct {
 type Event struct {
 	ID string `gorm:"primary_key" json:"id,omitempty"`
 
+	// UID identifies an Event imported from iCalendar, so re-importing it updates the Event.
+	UID string `gorm:"index" json:"uid,omitempty"`
+
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
 	Website     string `json:"website,omitempty"`
@@ -30,10 +50,41 @@ type Event struct {
 
 	TimeSlot *TimeSlot `gorm:"embedded" json:"time-slot,omitempty"`
 
+	// Optional RFC 5545 recurrence of the TimeSlot, e.g. "FREQ=WEEKLY;BYDAY=TU;COUNT=10".
+	RRule   string   `json:"rrule,omitempty"`
+	ExDates TimeList `json:"exdates,omitempty"` // starts excluded from the RRule
+	RDates  TimeList `json:"rdates,omitempty"`  // starts added to the RRule
+
 	Status EventStatus `json:"status,omitempty"`
 
+	// PreviousStatus is the status a canceled Event is restored to when it's uncanceled.
+	PreviousStatus EventStatus `json:"-"`
+
+	// Version is incremented on every change and used as the Event's ETag.
+	Version int `gorm:"not null;default:1" json:"version,omitempty"`
+
 	CreatedAt     time.Time `json:"created-at,omitempty"`
 	UpdatedAt     time.Time `json:"updated-at,omitempty"`
 	CanceledAt    time.Time `json:"canceled-at,omitempty"`
 	RescheduledAt time.Time `json:"rescheduled-at,omitempty"`
+
+	// DeletedAt is set while the Event is in the trash, which hides it until it's restored or purged.
+	// Only deleting an Event moves it to the trash, so DeletedAt is never read from or written to JSON.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Match is only set when the Event was listed by a full-text search.
+	Match *SearchMatch `gorm:"-" json:"match,omitempty"`
+}
+
+// SearchMatch holds how well an Event matched a full-text search.
+type SearchMatch struct {
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet,omitempty"` // matched words are wrapped in <mark> tags
+}
+
+// SearchTerms splits a full-text search into lowercase words, dropping punctuation.
+func SearchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
 }
@@ -0,0 +1,22 @@
+package objects
+
+import "time"
+
+// Feed is a saved list of Events that calendar clients subscribe to by its Token.
+type Feed struct {
+	Token string `gorm:"primary_key" json:"token,omitempty"`
+	Name  string `json:"name,omitempty"`
+
+	// Filter holds the GET /events query parameters of the list, e.g. "status=canceled&q=jazz".
+	Filter string `json:"filter"`
+
+	CreatedAt time.Time `json:"created-at,omitempty"`
+
+	// ETag and ModifiedAt are the validators of the Feed's events when it was last served, so
+	// Last-Modified moves forward when an Event leaves the list, which none of the rest tell.
+	ETag       string    `gorm:"column:etag" json:"-"`
+	ModifiedAt time.Time `json:"-"`
+
+	// URL is where the Feed is served, and is only set when the Feed is created.
+	URL string `gorm:"-" json:"url,omitempty"`
+}
@@ -0,0 +1,196 @@
+package objects
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// HistoryOp is the kind of change recorded by a HistoryEntry.
+type HistoryOp string
+
+// History operations, one for each way an Event is changed.
+const (
+	OpCreate               HistoryOp = "create"
+	OpUpdate               HistoryOp = "update"
+	OpPatch                HistoryOp = "patch"
+	OpCancel               HistoryOp = "cancel"
+	OpReschedule           HistoryOp = "reschedule"
+	OpTransition           HistoryOp = "transition" // publish, postpone or complete
+	OpUncancel             HistoryOp = "uncancel"
+	OpDelete               HistoryOp = "delete" // moves the Event to the trash
+	OpRestore              HistoryOp = "restore"
+	OpPurge                HistoryOp = "purge" // removes the Event from the trash for good
+	OpCancelOccurrence     HistoryOp = "cancel-occurrence"
+	OpRescheduleOccurrence HistoryOp = "reschedule-occurrence"
+)
+
+// Valid reports whether the operation is one of the known HistoryOp values.
+func (o HistoryOp) Valid() bool {
+	switch o {
+	case OpCreate, OpUpdate, OpPatch, OpCancel, OpReschedule, OpTransition, OpUncancel, OpDelete, OpRestore,
+		OpPurge, OpCancelOccurrence, OpRescheduleOccurrence:
+		return true
+	}
+
+	return false
+}
+
+// HistoryEntry records one change of an Event. Entries are only ever appended, so the Event as it
+// was at any time is the After of the latest entry up to then.
+type HistoryEntry struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	EventID   string    `gorm:"index" json:"event-id"`
+	Operation HistoryOp `json:"operation"`
+	Actor     string    `json:"actor,omitempty"` // who made the change, when known
+	At        time.Time `json:"at"`
+
+	// RecurrenceID is set for changes to one occurrence of a recurring Event.
+	RecurrenceID *time.Time `json:"recurrence-id,omitempty"`
+
+	// The Event before and after the change, nil before it's created and after it's deleted.
+	Before *Snapshot `json:"before"`
+	After  *Snapshot `json:"after"`
+
+	Changes Changes `json:"changes,omitempty"`
+}
+
+// TableName stores HistoryEntries in the event_history table.
+func (HistoryEntry) TableName() string {
+	return "event_history"
+}
+
+// Snapshot is an Event as it was at one point of its history, stored as JSON.
+type Snapshot Event
+
+// NewSnapshot returns a Snapshot of the event, or nil for no event.
+func NewSnapshot(event *Event) *Snapshot {
+	if event == nil {
+		return nil
+	}
+
+	snapshot := Snapshot(*event)
+	snapshot.Match = nil
+
+	return &snapshot
+}
+
+// Event returns the Event of the Snapshot, or nil for no Snapshot.
+func (s *Snapshot) Event() *Event {
+	if s == nil {
+		return nil
+	}
+
+	event := Event(*s)
+
+	return &event
+}
+
+// MarshalJSON marshals the Snapshot as its Event.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Event(s))
+}
+
+// UnmarshalJSON unmarshals the Snapshot from its Event.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*Event)(s))
+}
+
+// Value stores the Snapshot as JSON.
+func (s Snapshot) Value() (driver.Value, error) {
+	return jsonValue(s)
+}
+
+// Scan reads a Snapshot stored by Value.
+func (s *Snapshot) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// GormDataType stores a Snapshot in a text column, Postgres uses jsonb.
+func (Snapshot) GormDataType() string {
+	return "text"
+}
+
+// Change is the value of a field before and after a change.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Changes holds the changed fields of a HistoryEntry by their JSON names, stored as JSON.
+type Changes map[string]Change
+
+// Diff returns the fields that differ between the before and after Events, leaving out the version
+// and update time that change every time. Either Event is nil when it doesn't exist.
+func Diff(before, after *Event) Changes {
+	from, to := fields(before), fields(after)
+
+	changes := Changes{}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			to[name] = nil
+		}
+	}
+
+	for name, value := range to {
+		if name == "version" || name == "updated-at" || reflect.DeepEqual(from[name], value) {
+			continue
+		}
+
+		changes[name] = Change{From: from[name], To: value}
+	}
+
+	return changes
+}
+
+// fields returns the fields of an Event by their JSON names.
+func fields(event *Event) map[string]interface{} {
+	values := map[string]interface{}{}
+	if event == nil {
+		return values
+	}
+
+	data, _ := json.Marshal(NewSnapshot(event))
+	_ = json.Unmarshal(data, &values)
+
+	return values
+}
+
+// Value stores the Changes as JSON.
+func (c Changes) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+// Scan reads Changes stored by Value.
+func (c *Changes) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// GormDataType stores Changes in a text column, Postgres uses jsonb.
+func (Changes) GormDataType() string {
+	return "text"
+}
+
+func jsonValue(v interface{}) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func scanJSON(src interface{}, v interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	case []byte:
+		return json.Unmarshal(data, v)
+	default:
+		return fmt.Errorf("unsupported JSON source %T", src)
+	}
+}
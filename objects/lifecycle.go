@@ -0,0 +1,48 @@
+package objects
+
+// transitions holds the statuses each EventStatus can change to. Draft Events are published before
+// anything else, canceled Events can only be uncanceled back to their previous status and completed
+// Events are final.
+var transitions = map[EventStatus][]EventStatus{
+	Draft:       {Published},
+	Original:    {Rescheduled, Postponed, Canceled, Completed},
+	Published:   {Rescheduled, Postponed, Canceled, Completed},
+	Rescheduled: {Rescheduled, Postponed, Canceled, Completed},
+	Postponed:   {Rescheduled, Canceled},
+	Canceled:    {},
+	Completed:   {},
+}
+
+// CanTransition reports whether an Event with the status can change to the status to.
+func (s EventStatus) CanTransition(to EventStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Active reports whether the Event is going ahead, so its occurrences can be changed.
+func (s EventStatus) Active() bool {
+	switch s {
+	case Original, Published, Rescheduled:
+		return true
+	}
+
+	return false
+}
+
+// TransitionsTo returns the statuses that can change to the status to.
+func TransitionsTo(to EventStatus) []EventStatus {
+	var from []EventStatus
+
+	for _, s := range []EventStatus{Draft, Original, Published, Rescheduled, Postponed, Canceled, Completed} {
+		if s.CanTransition(to) {
+			from = append(from, s)
+		}
+	}
+
+	return from
+}
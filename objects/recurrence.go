@@ -0,0 +1,282 @@
+package objects
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+	"github.com/theantichris/events-api/errors"
+)
+
+// maxRecurrenceSteps is the most starts of a recurrence walked to expand it, which bounds the
+// rules whose earlier starts can't be skipped.
+const maxRecurrenceSteps = 100000
+
+// recurrenceTimeFormat is the RFC 5545 UTC date-time format used to store TimeLists.
+const recurrenceTimeFormat = "20060102T150405Z"
+
+// TimeList is a list of times stored as comma separated RFC 5545 UTC date-times.
+type TimeList []time.Time
+
+// Value stores the TimeList as text.
+func (l TimeList) Value() (driver.Value, error) {
+	values := make([]string, 0, len(l))
+	for _, t := range l {
+		values = append(values, t.UTC().Format(recurrenceTimeFormat))
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+// Scan reads a TimeList stored by Value.
+func (l *TimeList) Scan(src interface{}) error {
+	*l = nil
+
+	var value string
+
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported TimeList source %T", src)
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		if part == "" {
+			continue
+		}
+
+		t, err := time.Parse(recurrenceTimeFormat, part)
+		if err != nil {
+			return err
+		}
+
+		*l = append(*l, t)
+	}
+
+	return nil
+}
+
+// GormDataType stores a TimeList in a text column.
+func (TimeList) GormDataType() string {
+	return "text"
+}
+
+// Occurrence is a single instance of a recurring Event. Canceling or rescheduling an Occurrence
+// stores it as an override of the Event's recurrence, keyed by its original start.
+type Occurrence struct {
+	EventID      string    `gorm:"primary_key" json:"event-id"`
+	RecurrenceID time.Time `gorm:"primary_key" json:"recurrence-id"` // original start of the occurrence
+
+	TimeSlot *TimeSlot   `gorm:"embedded" json:"time-slot"`
+	Status   EventStatus `json:"status"`
+
+	CanceledAt    time.Time `json:"canceled-at,omitempty"`
+	RescheduledAt time.Time `json:"rescheduled-at,omitempty"`
+}
+
+// TableName stores Occurrence overrides in the event_occurrences table.
+func (Occurrence) TableName() string {
+	return "event_occurrences"
+}
+
+// Recurring reports whether the Event repeats.
+func (e *Event) Recurring() bool {
+	return e.RRule != "" || len(e.RDates) > 0
+}
+
+// Recurrence builds the set of occurrence starts of a recurring Event, beginning with its TimeSlot.
+func (e *Event) Recurrence() (*rrule.Set, error) {
+	return e.recurrence(time.Time{})
+}
+
+// recurrence builds the Recurrence of the Event with its rule started shortly before at when the
+// starts before it can be skipped, so walking the set doesn't begin at the first start.
+func (e *Event) recurrence(at time.Time) (*rrule.Set, error) {
+	if e.TimeSlot == nil {
+		return nil, fmt.Errorf("recurring event has no time slot")
+	}
+
+	set := &rrule.Set{}
+	set.DTStart(e.TimeSlot.Start)
+
+	if e.RRule != "" {
+		option, err := rrule.StrToROption(e.RRule)
+		if err != nil {
+			return nil, err
+		}
+
+		option.Dtstart = skipTo(option, e.TimeSlot.Start.Truncate(time.Second), at)
+
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, err
+		}
+
+		set.RRule(rule)
+	} else {
+		set.RDate(e.TimeSlot.Start)
+	}
+
+	for _, t := range e.RDates {
+		set.RDate(t)
+	}
+
+	for _, t := range e.ExDates {
+		set.ExDate(t)
+	}
+
+	return set, nil
+}
+
+// periods are the lengths of the frequencies whose rules can start at any later period.
+var periods = map[rrule.Frequency]time.Duration{
+	rrule.WEEKLY:   7 * 24 * time.Hour,
+	rrule.DAILY:    24 * time.Hour,
+	rrule.HOURLY:   time.Hour,
+	rrule.MINUTELY: time.Minute,
+}
+
+// skipTo returns the start of the last whole period of the rule that begins at least one period
+// before at, which yields the same starts from there on as dtstart does. Months and years vary in
+// length, and a COUNT depends on every earlier start, so those rules keep their dtstart.
+func skipTo(option *rrule.ROption, dtstart, at time.Time) time.Time {
+	period, ok := periods[option.Freq]
+	if !ok || option.Count != 0 {
+		return dtstart
+	}
+
+	if option.Interval > 1 {
+		period *= time.Duration(option.Interval)
+	}
+
+	skipped := at.Sub(dtstart)/period - 1
+	if skipped <= 0 {
+		return dtstart
+	}
+
+	start := dtstart.Add(skipped * period)
+
+	// A day is not 24 hours across a daylight saving change, so only skip between equal offsets.
+	_, offset := dtstart.Zone()
+	if _, skippedOffset := start.Zone(); skippedOffset != offset {
+		return dtstart
+	}
+
+	return start
+}
+
+// CheckRecurrence returns why the recurrence of the Event is invalid. Rules repeating more often
+// than every minute are rejected, since expanding them walks too many starts.
+func (e *Event) CheckRecurrence() error {
+	if _, err := e.Recurrence(); err != nil {
+		return err
+	}
+
+	if e.RRule == "" {
+		return nil
+	}
+
+	option, err := rrule.StrToROption(e.RRule)
+	if err != nil {
+		return err
+	}
+
+	if option.Freq == rrule.SECONDLY {
+		return fmt.Errorf("recurrence is more frequent than every minute")
+	}
+
+	return nil
+}
+
+// IsOccurrence reports whether start is an original start of the recurring Event. Starts more than
+// maxRecurrenceSteps into the recurrence aren't found.
+func (e *Event) IsOccurrence(start time.Time) bool {
+	set, err := e.recurrence(start)
+	if err != nil {
+		return false
+	}
+
+	// The starts come in order, so stop at the first one past start.
+	next := set.Iterator()
+	steps := 0
+	for t, ok := next(); ok && !t.After(start) && steps < maxRecurrenceSteps; t, ok = next() {
+		if t.Equal(start) {
+			return true
+		}
+
+		steps++
+	}
+
+	return false
+}
+
+// Occurrences expands the Event into the occurrences that overlap the window from to to,
+// applying the canceled and rescheduled overrides. At most MaxListLimit occurrences are returned,
+// and ErrRecurrenceTooLong when more than maxRecurrenceSteps starts come before them.
+func (e *Event) Occurrences(overrides []*Occurrence, from, to time.Time) ([]*Occurrence, error) {
+	duration := e.TimeSlot.End.Sub(e.TimeSlot.Start)
+
+	// Occurrences starting up to one duration before the window still overlap it.
+	set, err := e.recurrence(from.Add(-duration))
+	if err != nil {
+		return nil, err
+	}
+
+	byStart := map[int64]*Occurrence{}
+	for _, override := range overrides {
+		byStart[override.RecurrenceID.Unix()] = override
+	}
+
+	var occurrences []*Occurrence
+
+	// The starts come in order, so stop at the end of the window, or once the list is full since
+	// any later start wouldn't make the cut below.
+	next := set.Iterator()
+	steps := 0
+	for start, ok := next(); ok && start.Before(to) && len(occurrences) < MaxListLimit; start, ok = next() {
+		if steps++; steps > maxRecurrenceSteps {
+			return nil, errors.ErrRecurrenceTooLong
+		}
+
+		if _, ok := byStart[start.Unix()]; ok {
+			continue
+		}
+
+		if !start.Add(duration).After(from) {
+			continue
+		}
+
+		occurrences = append(occurrences, &Occurrence{
+			EventID:      e.ID,
+			RecurrenceID: start,
+			TimeSlot:     &TimeSlot{Start: start, End: start.Add(duration)},
+			Status:       e.Status,
+		})
+	}
+
+	// Overrides are matched on their own time slot, which may have moved into the window.
+	for _, override := range overrides {
+		slot := override.TimeSlot
+		if slot != nil && slot.Start.Before(to) && slot.End.After(from) {
+			occurrences = append(occurrences, override)
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].TimeSlot.Start.Before(occurrences[j].TimeSlot.Start)
+	})
+
+	if len(occurrences) > MaxListLimit {
+		occurrences = occurrences[:MaxListLimit]
+	}
+
+	return occurrences, nil
+}
@@ -3,6 +3,7 @@ package objects
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 // MaxListLimit holds the maximum number of listings.
@@ -10,14 +11,58 @@ const MaxListLimit = 200
 
 // GetRequest is for retrieving a single Event.
 type GetRequest struct {
-	ID string `json:"id"`
+	ID  string `json:"id"`
+	UID string `json:"uid"` // gets the Event by its iCalendar UID instead
+
+	// AsOf gets the Event as it was at a past time from its history instead.
+	AsOf time.Time `json:"as-of"`
+
+	// Deleted gets the Event from the trash instead.
+	Deleted bool `json:"deleted"`
 }
 
-// ListRequest is for getting a list of Events.
+// ListRequest is for getting a list of Events. All optional filters are combined.
 type ListRequest struct {
 	Limit int    `json:"limit"`
-	After string `json:"after"` // for paging
+	After string `json:"after"` // for paging by ID
 	Name  string `json:"name"`  // optional name matching
+	Query string `json:"q"`     // optional ranked full-text search over name, description and address
+
+	Sort   Sort    `json:"sort"`   // order of the list, by ID when empty
+	Cursor *Cursor `json:"cursor"` // for paging in any Sort
+
+	SkipTotal bool `json:"skip-total"` // skips counting all matching Events on large tables
+
+	Deleted bool `json:"deleted"` // lists the Events in the trash instead
+
+	Status      []EventStatus `json:"status"`      // optional status matching, any of
+	Address     string        `json:"address"`     // optional address substring matching
+	Description string        `json:"description"` // optional description substring matching
+
+	// Optional window that the Event's TimeSlot overlaps.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Optional created and updated ranges, inclusive.
+	CreatedAfter  time.Time `json:"created-after"`
+	CreatedBefore time.Time `json:"created-before"`
+	UpdatedAfter  time.Time `json:"updated-after"`
+	UpdatedBefore time.Time `json:"updated-before"`
+}
+
+// PageLimit returns the Limit, or MaxListLimit when the Limit is unset or too large.
+func (r ListRequest) PageLimit() int {
+	return PageLimit(r.Limit)
+}
+
+// PageLimit returns the limit of a page, or MaxListLimit when the limit isn't positive or is too
+// large.
+func PageLimit(limit int) int {
+	if limit <= 0 || limit > MaxListLimit {
+		return MaxListLimit
+	}
+
+	return limit
 }
 
 // CreateRequest is for creating a new Event.
@@ -33,29 +78,270 @@ type UpdateRequest struct {
 	Website     string `json:"website"`
 	Address     string `json:"address"`
 	PhoneNumber string `json:"phone-number"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// PatchRequest is for partially updating an existing Event with a JSON Merge Patch (RFC 7396).
+type PatchRequest struct {
+	ID          string      `json:"-"`
+	Name        PatchString `json:"name"`
+	Description PatchString `json:"description"`
+	Website     PatchString `json:"website"`
+	Address     PatchString `json:"address"`
+	PhoneNumber PatchString `json:"phone-number"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// PatchString is a string field of a PatchRequest that tells an absent field from an explicit null.
+type PatchString struct {
+	Set   bool
+	Value *string
+}
+
+// UnmarshalJSON is only called for fields present in the patch, so it marks the field as set.
+func (p *PatchString) UnmarshalJSON(data []byte) error {
+	p.Set = true
+
+	if string(data) == "null" {
+		p.Value = nil
+		return nil
+	}
+
+	return json.Unmarshal(data, &p.Value)
+}
+
+// String returns the patched value, an explicit null removes the value.
+func (p PatchString) String() string {
+	if p.Value == nil {
+		return ""
+	}
+
+	return *p.Value
+}
+
+// Apply merges the patch into the event and unsets patch fields that don't change it.
+func (r *PatchRequest) Apply(event *Event) {
+	applyString(&r.Name, &event.Name)
+	applyString(&r.Description, &event.Description)
+	applyString(&r.Website, &event.Website)
+	applyString(&r.Address, &event.Address)
+	applyString(&r.PhoneNumber, &event.PhoneNumber)
+}
+
+// Empty reports whether the patch changes nothing.
+func (r PatchRequest) Empty() bool {
+	return !r.Name.Set && !r.Description.Set && !r.Website.Set && !r.Address.Set && !r.PhoneNumber.Set
+}
+
+func applyString(field *PatchString, value *string) {
+	if !field.Set {
+		return
+	}
+
+	if field.String() == *value {
+		*field = PatchString{}
+		return
+	}
+
+	*value = field.String()
 }
 
 // CancelRequest is for canceling an existing Event.
 type CancelRequest struct {
 	ID string `json:"id"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// TransitionRequest is for publishing, postponing or completing an existing Event.
+type TransitionRequest struct {
+	ID     string      `json:"id"`
+	Status EventStatus `json:"status"` // one of Published, Postponed or Completed
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// UncancelRequest is for restoring a canceled Event to its status before it was canceled.
+type UncancelRequest struct {
+	ID string `json:"id"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
 }
 
 // RescheduleRequest is for rescheduling an existing Event.
 type RescheduleRequest struct {
 	ID          string    `json:"id"`
 	NewTimeSlot *TimeSlot `json:"new-time-slot"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// OccurrencesRequest is for expanding a recurring Event into its occurrences that overlap a window.
+type OccurrencesRequest struct {
+	ID   string    `json:"id"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// OverridesRequest is for listing the overridden occurrences of recurring Events.
+type OverridesRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// CancelOccurrenceRequest is for canceling one occurrence of a recurring Event.
+type CancelOccurrenceRequest struct {
+	ID           string    `json:"id"`
+	RecurrenceID time.Time `json:"recurrence-id"`
+}
+
+// RescheduleOccurrenceRequest is for rescheduling one occurrence of a recurring Event.
+type RescheduleOccurrenceRequest struct {
+	ID           string    `json:"id"`
+	RecurrenceID time.Time `json:"recurrence-id"`
+	NewTimeSlot  *TimeSlot `json:"new-time-slot"`
+}
+
+// CreateFeedRequest is for saving a new Feed.
+type CreateFeedRequest struct {
+	Feed *Feed `json:"feed"`
+}
+
+// GetFeedRequest is for retrieving a Feed by its token.
+type GetFeedRequest struct {
+	Token string `json:"token"`
+}
+
+// UpdateFeedRequest is for recording the validators of a Feed's events when they change.
+type UpdateFeedRequest struct {
+	Token      string    `json:"token"`
+	ETag       string    `json:"etag"`
+	ModifiedAt time.Time `json:"modified-at"`
+}
+
+// RevokeFeedRequest is for deleting a Feed so its token stops working.
+type RevokeFeedRequest struct {
+	Token string `json:"token"`
+}
+
+// CreateWebhookRequest is for subscribing a new Webhook to the changes of Events.
+type CreateWebhookRequest struct {
+	Webhook *Webhook `json:"webhook"`
+}
+
+// GetWebhookRequest is for retrieving a single Webhook.
+type GetWebhookRequest struct {
+	ID string `json:"id"`
+}
+
+// UpdateWebhookRequest is for changing where a Webhook is delivered and what to.
+type UpdateWebhookRequest struct {
+	ID         string        `json:"-"`
+	URL        string        `json:"url"`
+	Operations OperationList `json:"operations"`
+}
+
+// DeleteWebhookRequest is for unsubscribing a Webhook, which drops its undelivered Deliveries.
+type DeleteWebhookRequest struct {
+	ID string `json:"id"`
+}
+
+// ListDeliveriesRequest is for listing the latest Deliveries to a Webhook.
+type ListDeliveriesRequest struct {
+	WebhookID string         `json:"webhook-id"`
+	Status    DeliveryStatus `json:"status"` // optional status matching
+	Limit     int            `json:"limit"`
+}
+
+// PageLimit returns the Limit, or MaxListLimit when the Limit is unset or too large.
+func (r ListDeliveriesRequest) PageLimit() int {
+	return PageLimit(r.Limit)
+}
+
+// RetryDeliveryRequest is for attempting a dead Delivery again.
+type RetryDeliveryRequest struct {
+	WebhookID string `json:"webhook-id"`
+	ID        int64  `json:"id"`
+}
+
+// ClaimDeliveriesRequest is for taking the pending Deliveries that are due, so they aren't
+// attempted by others until the Lease runs out.
+type ClaimDeliveriesRequest struct {
+	Limit int           `json:"limit"`
+	Lease time.Duration `json:"lease"`
+}
+
+// CompleteDeliveryRequest is for saving the outcome of an attempted Delivery.
+type CompleteDeliveryRequest struct {
+	Delivery *Delivery `json:"delivery"`
 }
 
 // DeleteRequest is for deleting an existing Event.
 type DeleteRequest struct {
 	ID string `json:"id"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// RestoreRequest is for restoring a deleted Event from the trash.
+type RestoreRequest struct {
+	ID string `json:"id"`
+
+	// Version is the expected current version of the Event, 0 skips the check.
+	Version int `json:"-"`
+}
+
+// PurgeRequest is for permanently removing the Events deleted before a time from the trash.
+type PurgeRequest struct {
+	DeletedBefore time.Time `json:"deleted-before"`
+}
+
+// HistoryRequest is for listing the changes of an Event, oldest first.
+type HistoryRequest struct {
+	ID    string `json:"id"`
+	Limit int    `json:"limit"`
+	After int64  `json:"after"` // for paging by HistoryEntry ID
+}
+
+// PageLimit returns the Limit, or MaxListLimit when the Limit is unset or too large.
+func (r HistoryRequest) PageLimit() int {
+	return PageLimit(r.Limit)
+}
+
+// ListMeta holds the pagination details of a list of Events.
+type ListMeta struct {
+	Total      *int64 `json:"total,omitempty"` // all matching Events, ignoring paging
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // EventResponse holds the response to any event request.
 type EventResponse struct {
-	Event  *Event   `json:"event,omitempty"`
-	Events []*Event `json:"events,omitempty"`
-	Code   int      `json:"-"`
+	Event  *Event    `json:"event,omitempty"`
+	Events []*Event  `json:"events,omitempty"`
+	Meta   *ListMeta `json:"meta,omitempty"`
+
+	Occurrences []*Occurrence   `json:"occurrences,omitempty"`
+	Feed        *Feed           `json:"feed,omitempty"`
+	Results     []*ItemResult   `json:"results,omitempty"`
+	History     []*HistoryEntry `json:"history,omitempty"`
+	Webhook     *Webhook        `json:"webhook,omitempty"`
+	Webhooks    []*Webhook      `json:"webhooks,omitempty"`
+	Deliveries  []*Delivery     `json:"deliveries,omitempty"`
+
+	// Deprecated: NextCursor repeats Meta.NextCursor for clients of the first list responses, and
+	// will be removed.
+	NextCursor string `json:"next_cursor,omitempty"`
+	Code       int    `json:"-"`
 }
 
 func (e *EventResponse) Json() []byte {
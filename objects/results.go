@@ -0,0 +1,60 @@
+package objects
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+)
+
+// ItemStatus is the outcome of one item of a batch request.
+type ItemStatus string
+
+// Item statuses.
+const (
+	ItemCreated  ItemStatus = "created"
+	ItemUpdated  ItemStatus = "updated"
+	ItemCanceled ItemStatus = "canceled"
+	ItemDeleted  ItemStatus = "deleted"
+	ItemSkipped  ItemStatus = "skipped" // the item didn't change anything
+	ItemFailed   ItemStatus = "failed"
+)
+
+// ItemResult reports the outcome of one item of a batch request such as an import.
+type ItemResult struct {
+	Index int    `json:"index"` // position of the item in the request
+	ID    string `json:"id,omitempty"`
+	UID   string `json:"uid,omitempty"`
+
+	// RecurrenceID is set for items that change one occurrence of a recurring Event.
+	RecurrenceID *time.Time `json:"recurrence-id,omitempty"`
+
+	Status ItemStatus    `json:"status"`
+	Code   int           `json:"code"`            // HTTP status code of the item on its own
+	Error  *errors.Error `json:"error,omitempty"` // why the item failed
+}
+
+// Succeed marks the item with the status.
+func (r *ItemResult) Succeed(status ItemStatus) {
+	r.Status = status
+	r.Code = http.StatusOK
+	r.Error = nil
+
+	if status == ItemCreated {
+		r.Code = http.StatusCreated
+	}
+}
+
+// Fail marks the item as failed with the reason from the errors package, or as an internal error.
+func (r *ItemResult) Fail(err error) {
+	reason, ok := err.(*errors.Error)
+	if !ok {
+		log.Println(err)
+		reason = errors.ErrInternal
+	}
+
+	r.Status = ItemFailed
+	r.Code = reason.Code
+	r.Error = reason
+}
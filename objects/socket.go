@@ -0,0 +1,40 @@
+package objects
+
+import "github.com/theantichris/events-api/errors"
+
+// Types of SocketRequests.
+const (
+	SocketSubscribe   = "subscribe"
+	SocketUnsubscribe = "unsubscribe"
+)
+
+// SocketRequest is sent by WebSocket clients to subscribe to the changes of one Event, or of the
+// Events matching a filter, or to unsubscribe.
+type SocketRequest struct {
+	Type         string `json:"type"`
+	Subscription string `json:"subscription"` // named by the client, and unique per connection
+
+	// Only for subscribe, either the ID of an Event or the GET /events query parameters of a list.
+	EventID string `json:"event-id,omitempty"`
+	Filter  string `json:"filter,omitempty"`
+}
+
+// Types of SocketMessages besides changes, which have the type of the change.
+const (
+	SocketSubscribed   = "subscribed"
+	SocketUnsubscribed = "unsubscribed"
+	SocketError        = "error"
+)
+
+// SocketMessage is sent to WebSocket clients to answer a SocketRequest, or with a change of an
+// Event they subscribed to.
+type SocketMessage struct {
+	Type         string        `json:"type"`
+	Subscription string        `json:"subscription,omitempty"`
+	Error        *errors.Error `json:"error,omitempty"`
+
+	// Only for changes, the ID of the change, the subscriptions it matched and the Event.
+	ID            int64    `json:"id,omitempty"`
+	Subscriptions []string `json:"subscriptions,omitempty"`
+	Event         *Event   `json:"event,omitempty"`
+}
@@ -0,0 +1,153 @@
+package objects
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook is a subscription that has the changes of Events POSTed to its URL.
+type Webhook struct {
+	ID  string `gorm:"primary_key" json:"id,omitempty"`
+	URL string `json:"url"`
+
+	// Operations limits the changes delivered to these, every change is delivered when empty.
+	Operations OperationList `json:"operations,omitempty"`
+
+	// Secret signs the deliveries, and is only returned when the Webhook is created.
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"created-at,omitempty"`
+	UpdatedAt time.Time `json:"updated-at,omitempty"`
+}
+
+// Wants reports whether the changes made by the operation are delivered to the Webhook.
+func (w *Webhook) Wants(operation HistoryOp) bool {
+	if len(w.Operations) == 0 {
+		return true
+	}
+
+	for _, op := range w.Operations {
+		if op == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OperationList is a list of HistoryOps stored as comma separated text.
+type OperationList []HistoryOp
+
+// Value stores the OperationList as text.
+func (l OperationList) Value() (driver.Value, error) {
+	values := make([]string, 0, len(l))
+	for _, op := range l {
+		values = append(values, string(op))
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+// Scan reads an OperationList stored by Value.
+func (l *OperationList) Scan(src interface{}) error {
+	*l = nil
+
+	var value string
+
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported OperationList source %T", src)
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		if part != "" {
+			*l = append(*l, HistoryOp(part))
+		}
+	}
+
+	return nil
+}
+
+// GormDataType stores an OperationList in a text column.
+func (OperationList) GormDataType() string {
+	return "text"
+}
+
+// OutboxMessage is a change of an Event waiting to be delivered to the Webhooks. It's written in
+// the same transaction as the change, so no change is delivered that didn't happen or vice versa.
+type OutboxMessage struct {
+	ID        int64 `gorm:"primary_key"`
+	EventID   string
+	Operation HistoryOp
+	Payload   RawJSON // the HistoryEntry of the change
+	CreatedAt time.Time
+}
+
+// TableName stores OutboxMessages in the outbox table.
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
+
+// DeliveryStatus holds the state of a Delivery.
+type DeliveryStatus string
+
+// Delivery statuses.
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead" // gave up after too many failed attempts
+)
+
+// Delivery is an OutboxMessage on its way to one Webhook.
+type Delivery struct {
+	ID        int64     `gorm:"primary_key" json:"id"`
+	WebhookID string    `gorm:"index" json:"webhook-id"`
+	MessageID int64     `json:"message-id"` // the same for each Webhook the message is delivered to
+	EventID   string    `json:"event-id"`
+	Operation HistoryOp `json:"operation"`
+	Payload   RawJSON   `json:"payload"`
+
+	Status        DeliveryStatus `gorm:"index" json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next-attempt-at,omitempty"`
+	LastCode      int            `json:"last-code,omitempty"`  // HTTP status code of the last attempt
+	LastError     string         `json:"last-error,omitempty"` // why the last attempt failed
+
+	CreatedAt   time.Time `json:"created-at"`
+	DeliveredAt time.Time `json:"delivered-at,omitempty"`
+
+	// Webhook is the Webhook to deliver to, and is only set on claimed Deliveries.
+	Webhook *Webhook `gorm:"-" json:"-"`
+}
+
+// TableName stores Deliveries in the webhook_deliveries table.
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// RawJSON is JSON text that is marshaled as is.
+type RawJSON string
+
+// MarshalJSON returns the JSON text, or null when empty.
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if r == "" {
+		return []byte("null"), nil
+	}
+
+	return []byte(r), nil
+}
+
+// UnmarshalJSON keeps the JSON text.
+func (r *RawJSON) UnmarshalJSON(data []byte) error {
+	*r = RawJSON(data)
+
+	return nil
+}
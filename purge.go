@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// Defaults for how long deleted Events stay in the trash, and how often it's purged.
+const (
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// purge permanently removes the Events deleted more than retention ago every interval until ctx is done.
+func purge(ctx context.Context, st store.EventStore, retention, interval time.Duration) {
+	ctx = store.WithActor(ctx, "purge")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := st.Purge(ctx, objects.PurgeRequest{DeletedBefore: time.Now().Add(-retention)})
+		if err != nil {
+			log.Println("Unable to purge deleted events:", err)
+		} else if purged > 0 {
+			log.Println("Purged deleted events:", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
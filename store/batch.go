@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+// errBatchFailed rolls back the transaction of an atomic batch with a failed operation.
+var errBatchFailed = fmt.Errorf("batch operation failed")
+
+// batch applies the operations of an atomic batch in one transaction of the store.
+func batch(ctx context.Context, st EventStore, request objects.BatchRequest) ([]*objects.ItemResult, error) {
+	if !request.Atomic() {
+		results, _ := applyBatch(ctx, st, request.Operations, false)
+		return results, nil
+	}
+
+	var results []*objects.ItemResult
+
+	err := st.WithTx(ctx, func(tx EventStore) error {
+		var ok bool
+		if results, ok = applyBatch(ctx, tx, request.Operations, true); !ok {
+			return errBatchFailed
+		}
+
+		return nil
+	})
+	if err != nil && err != errBatchFailed {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// applyBatch applies the operations one by one through the store. When atomic, it stops at the
+// first failed operation, marks every other operation as aborted and reports that it failed.
+func applyBatch(ctx context.Context, st EventStore, operations []*objects.BatchOperation, atomic bool) ([]*objects.ItemResult, bool) {
+	results := make([]*objects.ItemResult, len(operations))
+
+	for i, operation := range operations {
+		results[i] = &objects.ItemResult{Index: i, ID: operation.ID}
+
+		status, err := applyOperation(ctx, st, operation)
+		if err == nil {
+			if operation.Op == objects.BatchCreate {
+				results[i].ID = operation.Event.ID
+			}
+
+			results[i].Succeed(status)
+			continue
+		}
+
+		results[i].Fail(err)
+
+		if atomic {
+			for j := range operations {
+				if j == i {
+					continue
+				}
+
+				// Created Events are rolled back, so their IDs are dropped.
+				results[j] = &objects.ItemResult{Index: j, ID: operations[j].ID}
+				results[j].Fail(errors.ErrBatchAborted)
+			}
+
+			return results, false
+		}
+	}
+
+	return results, true
+}
+
+// applyOperation applies one operation and returns the status of its ItemResult.
+func applyOperation(ctx context.Context, st EventStore, operation *objects.BatchOperation) (objects.ItemStatus, error) {
+	switch operation.Op {
+	case objects.BatchCreate:
+		return objects.ItemCreated, st.Create(ctx, objects.CreateRequest{Event: operation.Event})
+	case objects.BatchUpdate:
+		if operation.Event == nil {
+			return "", errors.ErrObjectIsRequired
+		}
+
+		return objects.ItemUpdated, st.Update(ctx, objects.UpdateRequest{
+			ID:          operation.ID,
+			Name:        operation.Event.Name,
+			Description: operation.Event.Description,
+			Website:     operation.Event.Website,
+			Address:     operation.Event.Address,
+			PhoneNumber: operation.Event.PhoneNumber,
+			Version:     operation.Version,
+		})
+	case objects.BatchCancel:
+		return objects.ItemCanceled, st.Cancel(ctx, objects.CancelRequest{ID: operation.ID, Version: operation.Version})
+	case objects.BatchDelete:
+		return objects.ItemDeleted, st.Delete(ctx, objects.DeleteRequest{ID: operation.ID, Version: operation.Version})
+	}
+
+	return "", errors.ErrInvalidBatch
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx whose changes are recorded in the Event history as made by actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns who makes the changes with ctx, empty when unknown.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+
+	return actor
+}
+
+// newEntry returns a HistoryEntry for the operation on an Event by the actor of ctx.
+func newEntry(ctx context.Context, id string, operation objects.HistoryOp) *objects.HistoryEntry {
+	return &objects.HistoryEntry{EventID: id, Operation: operation, Actor: Actor(ctx), Changes: objects.Changes{}}
+}
+
+// record completes the entry with the Event before and after the change made at the time.
+func record(entry *objects.HistoryEntry, before, after *objects.Event, at time.Time) {
+	entry.At = at
+	entry.Before = objects.NewSnapshot(before)
+	entry.After = objects.NewSnapshot(after)
+
+	for name, change := range objects.Diff(before, after) {
+		entry.Changes[name] = change
+	}
+}
+
+// occurrenceChange records the change of one occurrence of a recurring Event in the entry.
+func occurrenceChange(entry *objects.HistoryEntry, before, after *objects.Occurrence) {
+	recurrenceID := after.RecurrenceID
+	entry.RecurrenceID = &recurrenceID
+	entry.Changes["occurrence"] = objects.Change{From: before, To: after}
+}
+
+// asOf returns the Event as recorded by the latest of the entries, oldest first, up to t.
+func asOf(entries []*objects.HistoryEntry, t time.Time) (*objects.Event, error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].At.After(t) {
+			continue
+		}
+
+		if entries[i].After == nil {
+			return nil, errors.ErrEventNotFound
+		}
+
+		return entries[i].After.Event(), nil
+	}
+
+	return nil, errors.ErrEventNotFound
+}
+
+// historyPage trims entries fetched one past the page limit and sets the paging of meta.
+func historyPage(entries []*objects.HistoryEntry, request objects.HistoryRequest) ([]*objects.HistoryEntry, *objects.ListMeta) {
+	meta := &objects.ListMeta{Limit: request.PageLimit()}
+
+	if len(entries) > meta.Limit {
+		entries = entries[:meta.Limit]
+		meta.HasMore = true
+		meta.NextCursor = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+
+	return entries, meta
+}
@@ -0,0 +1,35 @@
+package store
+
+import "github.com/theantichris/events-api/objects"
+
+// initialStatus is the status of a new Event, which is created as a draft when asked to.
+func initialStatus(requested objects.EventStatus) objects.EventStatus {
+	if requested == objects.Draft {
+		return objects.Draft
+	}
+
+	return objects.Original
+}
+
+// transitionable reports whether the status is changed to by a TransitionRequest, rather than
+// by canceling, rescheduling or uncanceling.
+func transitionable(status objects.EventStatus) bool {
+	switch status {
+	case objects.Published, objects.Postponed, objects.Completed:
+		return true
+	}
+
+	return false
+}
+
+// uncanceledStatus is the status an Event is restored to when it's uncanceled. Events canceled
+// before their previous status was kept are restored as original.
+func uncanceledStatus(previous objects.EventStatus) objects.EventStatus {
+	for _, status := range objects.TransitionsTo(objects.Canceled) {
+		if status == previous {
+			return previous
+		}
+	}
+
+	return objects.Original
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/theantichris/events-api/objects"
+)
+
+// Listener is called with each change of an Event once it's committed, in the order they were
+// committed. It's called synchronously, so it must return quickly and not use the store.
+type Listener func(entry *objects.HistoryEntry)
+
+// listeners holds the Listeners of a store.
+type listeners struct {
+	mu  sync.RWMutex
+	fns []Listener
+}
+
+func (l *listeners) add(fn Listener) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fns = append(l.fns, fn)
+}
+
+// notify calls the Listeners with the committed entries.
+func (l *listeners) notify(entries ...*objects.HistoryEntry) {
+	if l == nil {
+		return
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, entry := range entries {
+		for _, fn := range l.fns {
+			fn(entry)
+		}
+	}
+}
@@ -0,0 +1,1162 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"gorm.io/gorm"
+)
+
+type memory struct {
+	mu        sync.RWMutex
+	events    map[string]*objects.Event
+	overrides map[string]map[int64]*objects.Occurrence // by event ID and recurrence ID in Unix seconds
+	feeds     map[string]*objects.Feed                 // by token
+	history   []*objects.HistoryEntry                  // oldest first
+
+	webhooks   map[string]*objects.Webhook
+	outbox     []*objects.OutboxMessage
+	deliveries map[int64]*objects.Delivery
+
+	// The IDs of the last OutboxMessage and Delivery.
+	messageID, deliveryID int64
+
+	listeners *listeners
+
+	// pending holds the changes made in a transaction by WithTx until it commits, and undo restores
+	// what it changed in the maps of the store if it doesn't.
+	pending *[]*objects.HistoryEntry
+	undo    *[]func()
+}
+
+// NewMemoryEventStore creates and returns an in-memory implementation of an EventStore.
+func NewMemoryEventStore() EventStore {
+	return &memory{
+		events:    map[string]*objects.Event{},
+		overrides: map[string]map[int64]*objects.Occurrence{},
+		feeds:     map[string]*objects.Feed{},
+
+		webhooks:   map[string]*objects.Webhook{},
+		deliveries: map[int64]*objects.Delivery{},
+		listeners:  &listeners{},
+	}
+}
+
+func (m *memory) Get(_ context.Context, request objects.GetRequest) (*objects.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !request.AsOf.IsZero() {
+		return asOf(m.entries(request.ID), request.AsOf)
+	}
+
+	if request.UID != "" {
+		for _, event := range m.events {
+			if event.UID == request.UID && event.DeletedAt.Valid == request.Deleted {
+				return clone(event), nil
+			}
+		}
+
+		return nil, errors.ErrEventNotFound
+	}
+
+	event, ok := m.events[request.ID]
+	if !ok || event.DeletedAt.Valid != request.Deleted {
+		return nil, errors.ErrEventNotFound
+	}
+
+	return clone(event), nil
+}
+
+// uidExists reports whether an Event that isn't deleted has the UID, which must be unique among them.
+func (m *memory) uidExists(uid string) bool {
+	if uid == "" {
+		return false
+	}
+
+	for _, event := range m.events {
+		if event.UID == uid && !event.DeletedAt.Valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *memory) List(_ context.Context, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := make([]*objects.Event, 0, len(m.events))
+	for _, event := range m.events {
+		events = append(events, clone(event))
+	}
+
+	list, meta := listEvents(events, request)
+
+	return list, meta, nil
+}
+
+// listEvents filters, searches, sorts and pages a list of events in memory.
+func listEvents(events []*objects.Event, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta) {
+	meta := &objects.ListMeta{Limit: request.PageLimit()}
+	terms := objects.SearchTerms(request.Query)
+
+	var total int64
+
+	list := make([]*objects.Event, 0, len(events))
+	for _, event := range events {
+		if !matches(event, request) {
+			continue
+		}
+
+		if len(terms) > 0 {
+			searchMatch, ok := match(event, terms)
+			if !ok {
+				continue
+			}
+
+			event.Match = searchMatch
+		}
+
+		total++
+
+		if request.After != "" && event.ID <= request.After {
+			continue
+		}
+
+		if request.Cursor != nil && !before(request.Sort, request.Cursor.Key, request.Cursor.ID, event) {
+			continue
+		}
+
+		list = append(list, event)
+	}
+
+	if !request.SkipTotal {
+		meta.Total = &total
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return before(request.Sort, request.Sort.Key(list[i]), list[i].ID, list[j])
+	})
+
+	if len(list) > meta.Limit+1 {
+		list = list[:meta.Limit+1]
+	}
+
+	return page(list, request, meta), meta
+}
+
+// before reports whether the position given by key and id comes before the event in the sort.
+func before(order objects.Sort, key, id string, event *objects.Event) bool {
+	eventKey := order.Key(event)
+	if key == eventKey {
+		return (id < event.ID) != order.Descending
+	}
+
+	return (key < eventKey) != order.Descending
+}
+
+func (m *memory) Create(ctx context.Context, request objects.CreateRequest) error {
+	if request.Event == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event := request.Event
+	if m.uidExists(event.UID) {
+		return errors.ErrEventUIDExists
+	}
+
+	event.ID = GenerateUniqueID()
+	event.Status = initialStatus(event.Status)
+	event.Version = 1
+	event.CreatedAt = time.Now()
+	event.UpdatedAt = event.CreatedAt
+
+	m.keepEvent(event.ID)
+	m.events[event.ID] = clone(event)
+	m.record(ctx, objects.OpCreate, nil, event)
+
+	return nil
+}
+
+func (m *memory) Update(ctx context.Context, request objects.UpdateRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.versioned(request.ID, request.Version)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+
+	event.Name = request.Name
+	event.Description = request.Description
+	event.Website = request.Website
+	event.Address = request.Address
+	event.PhoneNumber = request.PhoneNumber
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	m.record(ctx, objects.OpUpdate, before, event)
+
+	return nil
+}
+
+func (m *memory) Patch(ctx context.Context, request objects.PatchRequest) error {
+	if request.Empty() {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.versioned(request.ID, request.Version)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+
+	request.Apply(event)
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	m.record(ctx, objects.OpPatch, before, event)
+
+	return nil
+}
+
+func (m *memory) Cancel(ctx context.Context, request objects.CancelRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.transition(request.ID, request.Version, objects.Canceled)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+
+	event.PreviousStatus = event.Status
+	event.Status = objects.Canceled
+	event.CanceledAt = time.Now()
+	event.UpdatedAt = event.CanceledAt
+	event.Version++
+
+	m.record(ctx, objects.OpCancel, before, event)
+
+	return nil
+}
+
+func (m *memory) Reschedule(ctx context.Context, request objects.RescheduleRequest) error {
+	if request.NewTimeSlot == nil {
+		return errors.ErrEventTimingIsRequired
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.transition(request.ID, request.Version, objects.Rescheduled)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+
+	event.TimeSlot = cloneSlot(request.NewTimeSlot)
+	event.Status = objects.Rescheduled
+	event.RescheduledAt = time.Now()
+	event.UpdatedAt = event.RescheduledAt
+	event.Version++
+
+	m.record(ctx, objects.OpReschedule, before, event)
+
+	return nil
+}
+
+func (m *memory) Transition(ctx context.Context, request objects.TransitionRequest) error {
+	if !transitionable(request.Status) {
+		return errors.ErrInvalidStatus
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.transition(request.ID, request.Version, request.Status)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+
+	event.Status = request.Status
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	m.record(ctx, objects.OpTransition, before, event)
+
+	return nil
+}
+
+func (m *memory) Uncancel(ctx context.Context, request objects.UncancelRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.versioned(request.ID, request.Version)
+	if err != nil {
+		return err
+	}
+
+	if event.Status != objects.Canceled {
+		return errors.ErrInvalidTransition
+	}
+
+	before := clone(event)
+
+	event.Status = uncanceledStatus(event.PreviousStatus)
+	event.PreviousStatus = ""
+	event.CanceledAt = time.Time{}
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	m.record(ctx, objects.OpUncancel, before, event)
+
+	return nil
+}
+
+func (m *memory) Delete(ctx context.Context, request objects.DeleteRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.versioned(request.ID, request.Version)
+	if err != nil {
+		return err
+	}
+
+	before := clone(event)
+	event.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	m.record(ctx, objects.OpDelete, before, nil)
+
+	return nil
+}
+
+func (m *memory) Restore(ctx context.Context, request objects.RestoreRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, ok := m.events[request.ID]
+	if !ok || !event.DeletedAt.Valid {
+		return errors.ErrEventNotFound
+	}
+
+	if request.Version != 0 && event.Version != request.Version {
+		return errors.ErrPreconditionFailed
+	}
+
+	// Another Event may have taken the UID of the deleted one meanwhile.
+	if m.uidExists(event.UID) {
+		return errors.ErrEventUIDExists
+	}
+
+	m.keepEvent(request.ID)
+	event.DeletedAt = gorm.DeletedAt{}
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	m.record(ctx, objects.OpRestore, nil, event)
+
+	return nil
+}
+
+func (m *memory) Purge(ctx context.Context, request objects.PurgeRequest) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+
+	for id, event := range m.events {
+		if !event.DeletedAt.Valid || !event.DeletedAt.Time.Before(request.DeletedBefore) {
+			continue
+		}
+
+		m.keepEvent(id)
+		m.keepOverrides(id)
+		delete(m.events, id)
+		delete(m.overrides, id)
+
+		entry := newEntry(ctx, id, objects.OpPurge)
+		record(entry, nil, nil, time.Now())
+		m.append(entry)
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (m *memory) History(_ context.Context, request objects.HistoryRequest) ([]*objects.HistoryEntry, *objects.ListMeta, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := m.entries(request.ID)
+	if _, ok := m.events[request.ID]; !ok && len(all) == 0 {
+		return nil, nil, errors.ErrEventNotFound
+	}
+
+	entries := []*objects.HistoryEntry{}
+	for _, entry := range all {
+		if entry.ID > request.After && len(entries) <= request.PageLimit() {
+			e := *entry
+			entries = append(entries, &e)
+		}
+	}
+
+	list, meta := historyPage(entries, request)
+
+	return list, meta, nil
+}
+
+// entries returns the history of an Event, oldest first. The caller must hold the lock.
+func (m *memory) entries(id string) []*objects.HistoryEntry {
+	var entries []*objects.HistoryEntry
+	for _, entry := range m.history {
+		if entry.EventID == id {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// record appends the change of an Event from before to after, either of which is nil when the
+// Event doesn't exist, to the history. The caller must hold the write lock.
+func (m *memory) record(ctx context.Context, operation objects.HistoryOp, before, after *objects.Event) {
+	event := after
+	if event == nil {
+		event = before
+	}
+
+	entry := newEntry(ctx, event.ID, operation)
+
+	record(entry, clone(before), clone(after), time.Now())
+	m.append(entry)
+}
+
+// append adds the entry to the history, and its change to the outbox. The caller must hold the
+// write lock.
+func (m *memory) append(entry *objects.HistoryEntry) {
+	entry.ID = int64(len(m.history)) + 1
+	m.history = append(m.history, entry)
+
+	message := newMessage(entry)
+	m.messageID++
+	message.ID = m.messageID
+	m.outbox = append(m.outbox, message)
+
+	m.notify(entry)
+}
+
+// notify calls the Listeners with the entries, or holds them until the copy replaces the store.
+func (m *memory) notify(entries ...*objects.HistoryEntry) {
+	if m.pending != nil {
+		*m.pending = append(*m.pending, entries...)
+		return
+	}
+
+	m.listeners.notify(entries...)
+}
+
+func (m *memory) Listen(listener Listener) {
+	m.listeners.add(listener)
+}
+
+func (m *memory) Batch(ctx context.Context, request objects.BatchRequest) ([]*objects.ItemResult, error) {
+	return batch(ctx, m, request)
+}
+
+// WithTx runs fn against the store, and undoes its changes when fn fails. The store is locked
+// meanwhile, so transactions are serialized with each other and with other calls.
+func (m *memory) WithTx(_ context.Context, fn func(tx EventStore) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		pending []*objects.HistoryEntry
+		undo    []func()
+	)
+
+	// The transaction shares the maps of the store, which it changes in place, and has its own
+	// history, outbox and IDs, which are only kept when it succeeds.
+	tx := &memory{
+		events:    m.events,
+		overrides: m.overrides,
+		feeds:     m.feeds,
+		history:   m.history[:len(m.history):len(m.history)],
+
+		webhooks:   m.webhooks,
+		outbox:     m.outbox[:len(m.outbox):len(m.outbox)],
+		deliveries: m.deliveries,
+		messageID:  m.messageID,
+		deliveryID: m.deliveryID,
+
+		listeners: m.listeners,
+		pending:   &pending,
+		undo:      &undo,
+	}
+
+	if err := fn(tx); err != nil {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+
+		return err
+	}
+
+	m.history, m.outbox = tx.history, tx.outbox
+	m.messageID, m.deliveryID = tx.messageID, tx.deliveryID
+
+	m.notify(pending...)
+
+	return nil
+}
+
+// keepEvent keeps the Event before it's changed in a transaction, so it can be undone. The caller
+// must hold the write lock, as for the other keep methods.
+func (m *memory) keepEvent(id string) {
+	if m.undo == nil {
+		return
+	}
+
+	event, ok := m.events[id]
+	event = clone(event)
+
+	*m.undo = append(*m.undo, func() {
+		if ok {
+			m.events[id] = event
+		} else {
+			delete(m.events, id)
+		}
+	})
+}
+
+func (m *memory) keepOverrides(id string) {
+	if m.undo == nil {
+		return
+	}
+
+	overrides, ok := m.overrides[id]
+
+	kept := make(map[int64]*objects.Occurrence, len(overrides))
+	for recurrenceID, override := range overrides {
+		kept[recurrenceID] = cloneOccurrence(override)
+	}
+
+	*m.undo = append(*m.undo, func() {
+		if ok {
+			m.overrides[id] = kept
+		} else {
+			delete(m.overrides, id)
+		}
+	})
+}
+
+func (m *memory) keepFeed(token string) {
+	if m.undo == nil {
+		return
+	}
+
+	feed, ok := m.feeds[token]
+
+	var kept objects.Feed
+	if ok {
+		kept = *feed
+	}
+
+	*m.undo = append(*m.undo, func() {
+		if ok {
+			m.feeds[token] = &kept
+		} else {
+			delete(m.feeds, token)
+		}
+	})
+}
+
+func (m *memory) keepWebhook(id string) {
+	if m.undo == nil {
+		return
+	}
+
+	webhook, ok := m.webhooks[id]
+	if ok {
+		webhook = cloneWebhook(webhook)
+	}
+
+	*m.undo = append(*m.undo, func() {
+		if ok {
+			m.webhooks[id] = webhook
+		} else {
+			delete(m.webhooks, id)
+		}
+	})
+}
+
+func (m *memory) keepDelivery(id int64) {
+	if m.undo == nil {
+		return
+	}
+
+	delivery, ok := m.deliveries[id]
+
+	var kept objects.Delivery
+	if ok {
+		kept = *delivery
+	}
+
+	*m.undo = append(*m.undo, func() {
+		if ok {
+			m.deliveries[id] = &kept
+		} else {
+			delete(m.deliveries, id)
+		}
+	})
+}
+
+func (m *memory) ListOccurrences(_ context.Context, request objects.OccurrencesRequest) ([]*objects.Occurrence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	event, err := m.recurring(request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]*objects.Occurrence, 0, len(m.overrides[request.ID]))
+	for _, override := range m.overrides[request.ID] {
+		overrides = append(overrides, cloneOccurrence(override))
+	}
+
+	return event.Occurrences(overrides, request.From, request.To)
+}
+
+func (m *memory) ListOverrides(_ context.Context, request objects.OverridesRequest) ([]*objects.Occurrence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	overrides := []*objects.Occurrence{}
+	for _, id := range request.IDs {
+		for _, override := range m.overrides[id] {
+			overrides = append(overrides, cloneOccurrence(override))
+		}
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].EventID != overrides[j].EventID {
+			return overrides[i].EventID < overrides[j].EventID
+		}
+
+		return overrides[i].RecurrenceID.Before(overrides[j].RecurrenceID)
+	})
+
+	return overrides, nil
+}
+
+func (m *memory) CancelOccurrence(ctx context.Context, request objects.CancelOccurrenceRequest) error {
+	return m.override(ctx, objects.OpCancelOccurrence, request.ID, request.RecurrenceID, objects.Canceled, func(occurrence *objects.Occurrence) {
+		occurrence.Status = objects.Canceled
+		occurrence.CanceledAt = time.Now()
+	})
+}
+
+func (m *memory) RescheduleOccurrence(ctx context.Context, request objects.RescheduleOccurrenceRequest) error {
+	if request.NewTimeSlot == nil {
+		return errors.ErrEventTimingIsRequired
+	}
+
+	return m.override(ctx, objects.OpRescheduleOccurrence, request.ID, request.RecurrenceID, objects.Rescheduled, func(occurrence *objects.Occurrence) {
+		occurrence.TimeSlot = cloneSlot(request.NewTimeSlot)
+		occurrence.Status = objects.Rescheduled
+		occurrence.RescheduledAt = time.Now()
+	})
+}
+
+// recurring returns the stored event, which has to recur. The caller must hold the lock.
+func (m *memory) recurring(id string) (*objects.Event, error) {
+	event, ok := m.events[id]
+	if !ok || event.DeletedAt.Valid {
+		return nil, errors.ErrEventNotFound
+	}
+
+	if !event.Recurring() {
+		return nil, errors.ErrEventNotRecurring
+	}
+
+	return event, nil
+}
+
+// override changes the override of one occurrence of a recurring event to the status and bumps the
+// event's version.
+func (m *memory) override(ctx context.Context, operation objects.HistoryOp, id string, recurrenceID time.Time, to objects.EventStatus, change func(occurrence *objects.Occurrence)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, err := m.recurring(id)
+	if err != nil {
+		return err
+	}
+
+	if !event.Status.Active() {
+		return errors.ErrInvalidTransition
+	}
+
+	recurrenceID = recurrenceID.UTC()
+	if !event.IsOccurrence(recurrenceID) {
+		return errors.ErrOccurrenceNotFound
+	}
+
+	occurrence, ok := m.overrides[id][recurrenceID.Unix()]
+	if !ok {
+		occurrence = newOccurrence(event, recurrenceID)
+	}
+
+	if !occurrence.Status.CanTransition(to) {
+		return errors.ErrInvalidTransition
+	}
+
+	before, previous := clone(event), cloneOccurrence(occurrence)
+
+	m.keepEvent(id)
+	m.keepOverrides(id)
+	change(occurrence)
+
+	if m.overrides[id] == nil {
+		m.overrides[id] = map[int64]*objects.Occurrence{}
+	}
+	m.overrides[id][recurrenceID.Unix()] = occurrence
+
+	event.UpdatedAt = time.Now()
+	event.Version++
+
+	entry := newEntry(ctx, id, operation)
+	occurrenceChange(entry, previous, cloneOccurrence(occurrence))
+	record(entry, before, clone(event), event.UpdatedAt)
+	m.append(entry)
+
+	return nil
+}
+
+// transition returns the stored event if it can change to the status, see versioned.
+func (m *memory) transition(id string, version int, to objects.EventStatus) (*objects.Event, error) {
+	event, err := m.versioned(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.Status.CanTransition(to) {
+		return nil, errors.ErrInvalidTransition
+	}
+
+	return event, nil
+}
+
+// versioned returns the stored event to change unless it's deleted, checking it against version
+// when non-zero. The caller must hold the write lock.
+func (m *memory) versioned(id string, version int) (*objects.Event, error) {
+	event, ok := m.events[id]
+	if !ok || event.DeletedAt.Valid {
+		return nil, errors.ErrEventNotFound
+	}
+
+	if version != 0 && event.Version != version {
+		return nil, errors.ErrPreconditionFailed
+	}
+
+	m.keepEvent(id)
+
+	return event, nil
+}
+
+func (m *memory) CreateFeed(_ context.Context, request objects.CreateFeedRequest) error {
+	if request.Feed == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	feed := request.Feed
+	feed.Token = token
+	feed.CreatedAt = time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := *feed
+	m.keepFeed(feed.Token)
+	m.feeds[feed.Token] = &c
+
+	return nil
+}
+
+func (m *memory) GetFeed(_ context.Context, request objects.GetFeedRequest) (*objects.Feed, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	feed, ok := m.feeds[request.Token]
+	if !ok {
+		return nil, errors.ErrFeedNotFound
+	}
+
+	c := *feed
+
+	return &c, nil
+}
+
+func (m *memory) UpdateFeed(_ context.Context, request objects.UpdateFeedRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed, ok := m.feeds[request.Token]
+	if !ok {
+		return errors.ErrFeedNotFound
+	}
+
+	m.keepFeed(request.Token)
+	feed.ETag = request.ETag
+	feed.ModifiedAt = request.ModifiedAt
+
+	return nil
+}
+
+func (m *memory) RevokeFeed(_ context.Context, request objects.RevokeFeedRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.feeds[request.Token]; !ok {
+		return errors.ErrFeedNotFound
+	}
+
+	m.keepFeed(request.Token)
+	delete(m.feeds, request.Token)
+
+	return nil
+}
+
+func (m *memory) CreateWebhook(_ context.Context, request objects.CreateWebhookRequest) error {
+	if request.Webhook == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	secret, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	webhook := request.Webhook
+	webhook.ID = GenerateUniqueID()
+	webhook.Secret = secret
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keepWebhook(webhook.ID)
+	m.webhooks[webhook.ID] = cloneWebhook(webhook)
+
+	return nil
+}
+
+func (m *memory) GetWebhook(_ context.Context, request objects.GetWebhookRequest) (*objects.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	webhook, ok := m.webhooks[request.ID]
+	if !ok {
+		return nil, errors.ErrWebhookNotFound
+	}
+
+	return cloneWebhook(webhook), nil
+}
+
+func (m *memory) ListWebhooks(_ context.Context) ([]*objects.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	webhooks := make([]*objects.Webhook, 0, len(m.webhooks))
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, cloneWebhook(webhook))
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+func (m *memory) UpdateWebhook(_ context.Context, request objects.UpdateWebhookRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	webhook, ok := m.webhooks[request.ID]
+	if !ok {
+		return errors.ErrWebhookNotFound
+	}
+
+	m.keepWebhook(request.ID)
+	webhook.URL = request.URL
+	webhook.Operations = append(objects.OperationList(nil), request.Operations...)
+	webhook.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (m *memory) DeleteWebhook(_ context.Context, request objects.DeleteWebhookRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.webhooks[request.ID]; !ok {
+		return errors.ErrWebhookNotFound
+	}
+
+	m.keepWebhook(request.ID)
+	delete(m.webhooks, request.ID)
+
+	for id, delivery := range m.deliveries {
+		if delivery.WebhookID == request.ID {
+			m.keepDelivery(id)
+			delete(m.deliveries, id)
+		}
+	}
+
+	return nil
+}
+
+func (m *memory) ListDeliveries(_ context.Context, request objects.ListDeliveriesRequest) ([]*objects.Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.webhooks[request.WebhookID]; !ok {
+		return nil, errors.ErrWebhookNotFound
+	}
+
+	deliveries := []*objects.Delivery{}
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == request.WebhookID && (request.Status == "" || delivery.Status == request.Status) {
+			d := *delivery
+			deliveries = append(deliveries, &d)
+		}
+	}
+
+	// Latest first.
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+
+	if len(deliveries) > request.PageLimit() {
+		deliveries = deliveries[:request.PageLimit()]
+	}
+
+	return deliveries, nil
+}
+
+func (m *memory) RetryDelivery(_ context.Context, request objects.RetryDeliveryRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delivery, ok := m.deliveries[request.ID]
+	if !ok || delivery.WebhookID != request.WebhookID || delivery.Status != objects.DeliveryDead {
+		return errors.ErrDeliveryNotFound
+	}
+
+	m.keepDelivery(request.ID)
+	delivery.Status = objects.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	return nil
+}
+
+func (m *memory) ClaimDeliveries(_ context.Context, request objects.ClaimDeliveriesRequest) ([]*objects.Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	webhooks := make([]*objects.Webhook, 0, len(m.webhooks))
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+
+	for _, delivery := range fanOut(m.outbox, webhooks, now) {
+		m.deliveryID++
+		delivery.ID = m.deliveryID
+		m.keepDelivery(delivery.ID)
+		m.deliveries[delivery.ID] = delivery
+	}
+
+	m.outbox = nil
+
+	var claimed []*objects.Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == objects.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			claimed = append(claimed, delivery)
+		}
+	}
+
+	sort.Slice(claimed, func(i, j int) bool {
+		return claimed[i].ID < claimed[j].ID
+	})
+
+	if request.Limit > 0 && len(claimed) > request.Limit {
+		claimed = claimed[:request.Limit]
+	}
+
+	for i, delivery := range claimed {
+		d := *delivery
+		d.Webhook = cloneWebhook(m.webhooks[delivery.WebhookID])
+		claimed[i] = &d
+
+		m.keepDelivery(delivery.ID)
+		delivery.NextAttemptAt = now.Add(request.Lease)
+	}
+
+	return claimed, nil
+}
+
+func (m *memory) CompleteDelivery(_ context.Context, request objects.CompleteDeliveryRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The Webhook may have been deleted meanwhile.
+	if _, ok := m.deliveries[request.Delivery.ID]; !ok {
+		return nil
+	}
+
+	d := *request.Delivery
+	d.Webhook = nil
+	m.keepDelivery(d.ID)
+	m.deliveries[d.ID] = &d
+
+	return nil
+}
+
+func (m *memory) Close() error {
+	return nil
+}
+
+// Matches reports whether List would list the event for the filters and search of the request.
+func Matches(event *objects.Event, request objects.ListRequest) bool {
+	if !matches(event, request) {
+		return false
+	}
+
+	if terms := objects.SearchTerms(request.Query); len(terms) > 0 {
+		_, ok := match(event, terms)
+		return ok
+	}
+
+	return true
+}
+
+// matches reports whether the event passes the optional ListRequest filters.
+func matches(event *objects.Event, request objects.ListRequest) bool {
+	if event.DeletedAt.Valid != request.Deleted {
+		return false
+	}
+
+	if !contains(event.Name, request.Name) ||
+		!contains(event.Address, request.Address) ||
+		!contains(event.Description, request.Description) {
+		return false
+	}
+
+	if len(request.Status) > 0 {
+		found := false
+		for _, status := range request.Status {
+			found = found || event.Status == status
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if !request.From.IsZero() || !request.To.IsZero() {
+		if event.TimeSlot == nil {
+			return false
+		}
+
+		if !request.From.IsZero() && !event.TimeSlot.End.After(request.From) {
+			return false
+		}
+
+		if !request.To.IsZero() && !event.TimeSlot.Start.Before(request.To) {
+			return false
+		}
+	}
+
+	return inRange(event.CreatedAt, request.CreatedAfter, request.CreatedBefore) &&
+		inRange(event.UpdatedAt, request.UpdatedAfter, request.UpdatedBefore)
+}
+
+// contains is a case-insensitive substring match, an empty substr matches everything.
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// inRange reports whether t is within the inclusive range, zero bounds are open.
+func inRange(t, after, before time.Time) bool {
+	return (after.IsZero() || !t.Before(after)) && (before.IsZero() || !t.After(before))
+}
+
+// clone returns a deep copy of an Event so callers can't mutate stored state.
+func clone(event *objects.Event) *objects.Event {
+	if event == nil {
+		return nil
+	}
+
+	c := *event
+	c.TimeSlot = cloneSlot(event.TimeSlot)
+	c.ExDates = append(objects.TimeList(nil), event.ExDates...)
+	c.RDates = append(objects.TimeList(nil), event.RDates...)
+
+	return &c
+}
+
+func cloneOccurrence(occurrence *objects.Occurrence) *objects.Occurrence {
+	c := *occurrence
+	c.TimeSlot = cloneSlot(occurrence.TimeSlot)
+
+	return &c
+}
+
+func cloneWebhook(webhook *objects.Webhook) *objects.Webhook {
+	c := *webhook
+	c.Operations = append(objects.OperationList(nil), webhook.Operations...)
+
+	return &c
+}
+
+func cloneSlot(slot *objects.TimeSlot) *objects.TimeSlot {
+	if slot == nil {
+		return nil
+	}
+
+	c := *slot
+
+	return &c
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS events (
+    id             text PRIMARY KEY,
+    name           text,
+    description    text,
+    website        text,
+    address        text,
+    phone_number   text,
+    start          timestamptz,
+    "end"          timestamptz,
+    status         text,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    canceled_at    timestamptz,
+    rescheduled_at timestamptz
+);
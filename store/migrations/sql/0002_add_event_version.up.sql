@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(address, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS events_search_idx ON events USING GIN (search);
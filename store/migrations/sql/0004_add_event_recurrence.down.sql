@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS event_occurrences;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS rrule,
+    DROP COLUMN IF EXISTS exdates,
+    DROP COLUMN IF EXISTS rdates;
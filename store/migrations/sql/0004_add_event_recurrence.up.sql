@@ -0,0 +1,15 @@
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS rrule text,
+    ADD COLUMN IF NOT EXISTS exdates text,
+    ADD COLUMN IF NOT EXISTS rdates text;
+
+CREATE TABLE IF NOT EXISTS event_occurrences (
+    event_id       text REFERENCES events (id) ON DELETE CASCADE,
+    recurrence_id  timestamptz,
+    start          timestamptz,
+    "end"          timestamptz,
+    status         text,
+    canceled_at    timestamptz,
+    rescheduled_at timestamptz,
+    PRIMARY KEY (event_id, recurrence_id)
+);
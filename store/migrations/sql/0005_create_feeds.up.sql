@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS feeds (
+    token      text PRIMARY KEY,
+    name       text,
+    filter     text,
+    created_at timestamptz
+);
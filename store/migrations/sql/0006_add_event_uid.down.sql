@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS events_uid_idx;
+
+ALTER TABLE events DROP COLUMN IF EXISTS uid;
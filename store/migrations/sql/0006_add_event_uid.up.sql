@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS uid text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS events_uid_idx ON events (uid) WHERE uid <> '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS event_history;
+DROP FUNCTION IF EXISTS event_history_append_only();
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS event_history (
+    id            bigserial PRIMARY KEY,
+    event_id      text NOT NULL,
+    operation     text NOT NULL,
+    actor         text,
+    at            timestamptz NOT NULL,
+    recurrence_id timestamptz,
+    before        jsonb,
+    after         jsonb,
+    changes       jsonb
+);
+
+CREATE INDEX IF NOT EXISTS event_history_event_id_idx ON event_history (event_id, id);
+
+-- History is append-only.
+CREATE OR REPLACE FUNCTION event_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'event_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS event_history_append_only ON event_history;
+CREATE TRIGGER event_history_append_only
+    BEFORE UPDATE OR DELETE ON event_history
+    FOR EACH ROW EXECUTE FUNCTION event_history_append_only();
@@ -0,0 +1,9 @@
+-- Without the column, events in the trash would come back, so purge them.
+DELETE FROM events WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS events_uid_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS events_uid_idx ON events (uid) WHERE uid <> '';
+
+DROP INDEX IF EXISTS events_deleted_at_idx;
+
+ALTER TABLE events DROP COLUMN IF EXISTS deleted_at;
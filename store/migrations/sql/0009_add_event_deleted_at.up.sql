@@ -0,0 +1,7 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS events_deleted_at_idx ON events (deleted_at);
+
+-- Deleted events keep their UID, so it only has to be unique among the others.
+DROP INDEX IF EXISTS events_uid_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS events_uid_idx ON events (uid) WHERE uid <> '' AND deleted_at IS NULL;
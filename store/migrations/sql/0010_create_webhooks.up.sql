@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         text PRIMARY KEY,
+    url        text NOT NULL,
+    operations text,
+    secret     text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+-- Changes waiting to be fanned out to the webhooks, written in the same transaction as the change.
+CREATE TABLE IF NOT EXISTS outbox (
+    id         bigserial PRIMARY KEY,
+    event_id   text NOT NULL,
+    operation  text NOT NULL,
+    payload    jsonb NOT NULL,
+    created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    webhook_id      text NOT NULL,
+    message_id      bigint NOT NULL,
+    event_id        text NOT NULL,
+    operation       text NOT NULL,
+    payload         jsonb NOT NULL,
+    status          text NOT NULL,
+    attempts        integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_code       integer,
+    last_error      text,
+    created_at      timestamptz,
+    delivered_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE feeds DROP COLUMN IF EXISTS modified_at;
+ALTER TABLE feeds DROP COLUMN IF EXISTS etag;
@@ -0,0 +1,2 @@
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS etag text NOT NULL DEFAULT '';
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS modified_at timestamptz;
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/theantichris/events-api/objects"
+)
+
+// changesChannel is the Postgres channel the IDs of committed HistoryEntries are notified on.
+const changesChannel = "event_changes"
+
+// replayWindow is how many IDs before the latest change are replayed on reconnecting. IDs are
+// taken when a change is made but notified when it commits, so a change can commit after later
+// ones; the window covers the changes still in flight once a later one was passed on.
+const replayWindow = 1000
+
+// changes keeps track of the changes passed on to the Listeners, to replay the ones they missed on
+// reconnecting and pass on the others once.
+type changes struct {
+	started bool           // whether the first connection read the latest changes
+	last    int64          // the latest change passed on, or the latest one when first connected
+	seen    map[int64]bool // the changes passed on within replayWindow of last
+}
+
+// start marks the latest changes when first connected as passed on, since only changes committed
+// afterwards are notified.
+func (c *changes) start(ids []int64) {
+	c.started = true
+	c.seen = map[int64]bool{}
+
+	for _, id := range ids {
+		c.add(id)
+	}
+}
+
+// from returns the ID the changes to replay come after.
+func (c *changes) from() int64 {
+	if c.last < replayWindow {
+		return 0
+	}
+
+	return c.last - replayWindow
+}
+
+// add records that the change was passed on, and reports whether it's new. Changes before the
+// window aren't replayed, so they don't have to be remembered.
+func (c *changes) add(id int64) bool {
+	if c.seen[id] {
+		return false
+	}
+
+	if id > c.last {
+		c.last = id
+
+		for seen := range c.seen {
+			if seen <= c.from() {
+				delete(c.seen, seen)
+			}
+		}
+	}
+
+	if id > c.from() {
+		c.seen[id] = true
+	}
+
+	return true
+}
+
+// listen calls the Listeners with the changes committed by every replica until ctx is done. The
+// connection is retried with backoff when it's lost, and the changes committed meanwhile are
+// replayed from the history once it's back.
+func (p pg) listen(ctx context.Context, conn string) {
+	backoff := minBackoff
+
+	passed := &changes{}
+
+	for {
+		connected, err := p.receive(ctx, conn, passed)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if connected {
+			backoff = minBackoff
+		}
+
+		log.Printf("Unable to listen for changes, retrying in %s: %s\n", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// receive listens on a connection of its own until it fails, and reports whether it connected.
+// Once listening, it replays the changes the Listeners missed, or starts from the latest changes on
+// the first connection.
+func (p pg) receive(ctx context.Context, conn string, passed *changes) (bool, error) {
+	listener, err := pgx.Connect(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+	defer listener.Close(context.Background())
+
+	if _, err := listener.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return false, err
+	}
+
+	// Changes committed after LISTEN are also notified, so they're only passed on once.
+	if !passed.started {
+		var ids []int64
+		if err := p.db.WithContext(ctx).Model(&objects.HistoryEntry{}).Order("id desc").Limit(replayWindow).Pluck("id", &ids).Error; err != nil {
+			return true, err
+		}
+
+		passed.start(ids)
+	} else {
+		var missed []*objects.HistoryEntry
+		if err := p.db.WithContext(ctx).Where("id > ?", passed.from()).Order("id").Find(&missed).Error; err != nil {
+			return true, err
+		}
+
+		for _, entry := range missed {
+			if passed.add(entry.ID) {
+				p.listeners.notify(entry)
+			}
+		}
+	}
+
+	for {
+		notification, err := listener.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		id, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			log.Println("Unable to parse a change notification:", err)
+			continue
+		}
+
+		if passed.seen[id] {
+			continue
+		}
+
+		entry := &objects.HistoryEntry{}
+		if err := p.db.WithContext(ctx).Take(entry, "id = ?", id).Error; err != nil {
+			log.Println("Unable to load a notified change:", err)
+			continue
+		}
+
+		if passed.add(entry.ID) {
+			p.listeners.notify(entry)
+		}
+	}
+}
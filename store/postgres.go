@@ -2,22 +2,101 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgconn"
+	"github.com/mattn/go-sqlite3"
 	"github.com/theantichris/events-api/errors"
 	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
+// uniqueViolation is the SQLSTATE of inserts that break a unique index.
+const uniqueViolation = "23505"
+
+// uidIndex is the unique index on the UIDs of Events that aren't deleted.
+const uidIndex = "events_uid_idx"
+
+// Bounds for the delay between connection attempts.
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 5 * time.Second
+)
+
 type pg struct {
 	db *gorm.DB
+
+	// forUpdate locks the rows read by Get until the transaction of db ends.
+	forUpdate bool
+
+	// rowLocks is whether the database supports SELECT ... FOR UPDATE.
+	rowLocks bool
+
+	listeners *listeners
+
+	// pending holds the changes made in the transaction of db until it's committed.
+	pending *[]*objects.HistoryEntry
+
+	// notifies is whether changes reach the Listeners through Postgres NOTIFY, so the Listeners of
+	// every replica hear them, and stopListening stops receiving them.
+	notifies      bool
+	stopListening func()
 }
 
 // NewPostgresEventStore creates and returns a Postgres implementation of an EventStore.
-func NewPostgresEventStore(conn string) EventStore {
+// The initial connection is retried with backoff until retryWindow has elapsed.
+func NewPostgresEventStore(conn string, retryWindow time.Duration) (EventStore, error) {
+	db, err := open(postgres.Open(conn), retryWindow)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the database: %w", err)
+	}
+
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("unable to load migrations: %w", err)
+	}
+
+	if err := migrator.Up(context.Background()); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("unable to migrate database: %w", err)
+	}
+
+	p := &pg{db: db, rowLocks: true, listeners: &listeners{}, notifies: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		p.listen(ctx, conn)
+	}()
+
+	p.stopListening = func() {
+		cancel()
+		<-done
+	}
+
+	return p, nil
+}
+
+// open opens a gorm connection, retrying with exponential backoff until retryWindow has elapsed.
+func open(dialector gorm.Dialector, retryWindow time.Duration) (*gorm.DB, error) {
 	config := &gorm.Config{
 		Logger: logger.New(
 			log.New(os.Stdout, "", log.LstdFlags),
@@ -28,23 +107,62 @@ func NewPostgresEventStore(conn string) EventStore {
 		),
 	}
 
-	db, err := gorm.Open(postgres.Open(conn), config)
+	deadline := time.Now().Add(retryWindow)
+	backoff := minBackoff
 
-	if err != nil {
-		panic("Unable to connect to the database: " + err.Error())
+	for {
+		db, err := gorm.Open(dialector, config)
+		if err == nil {
+			return db, nil
+		}
+
+		// A failed ping still opens a connection pool, which would leak with every attempt.
+		closeDB(db)
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+
+		log.Printf("Unable to connect to the database, retrying in %s: %s\n", backoff, err)
+		time.Sleep(backoff)
+
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
 	}
+}
 
-	if err := db.AutoMigrate(&objects.Event{}); err != nil {
-		panic("Unable to migrate database: " + err.Error())
+// closeDB closes the connection pool of db, if it has one.
+func closeDB(db *gorm.DB) {
+	if db == nil {
+		return
 	}
 
-	return &pg{db}
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
 }
 
 func (p pg) Get(ctx context.Context, request objects.GetRequest) (*objects.Event, error) {
+	if !request.AsOf.IsZero() {
+		return p.asOf(ctx, request)
+	}
+
 	event := &objects.Event{}
 
-	err := p.db.WithContext(ctx).Take(event, "id = ?", request.ID).Error
+	query := p.locking(p.db.WithContext(ctx))
+
+	if request.UID != "" {
+		query = query.Where("uid = ?", request.UID)
+	} else {
+		query = query.Where("id = ?", request.ID)
+	}
+
+	if request.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	err := query.Take(event).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, errors.ErrEventNotFound
 	}
@@ -52,27 +170,249 @@ func (p pg) Get(ctx context.Context, request objects.GetRequest) (*objects.Event
 	return event, err
 }
 
-func (p pg) List(ctx context.Context, request objects.ListRequest) ([]*objects.Event, error) {
-	limit := request.Limit
-	if limit == 0 || limit > objects.MaxListLimit {
-		limit = objects.MaxListLimit
+// locking locks the rows read by the query when forUpdate is set.
+func (p pg) locking(query *gorm.DB) *gorm.DB {
+	if p.forUpdate {
+		return query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	return query
+}
+
+func (p pg) List(ctx context.Context, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta, error) {
+	if request.Query != "" {
+		return p.search(ctx, request)
+	}
+
+	return list(p.db.WithContext(ctx), request, "ilike")
+}
+
+// list pages through events using the given case-insensitive like operator for substring matching.
+func list(db *gorm.DB, request objects.ListRequest, like string) ([]*objects.Event, *objects.ListMeta, error) {
+	meta, err := count(filter(db.Model(&objects.Event{}), request, like), request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Fetch one extra event to tell whether there's another page.
+	query, err := paginate(filter(db.Limit(meta.Limit+1), request, like), request, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := make([]*objects.Event, 0, meta.Limit+1)
+
+	if err := query.Find(&list).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return page(list, request, meta), meta, nil
+}
+
+// tsquery is the Postgres full-text query for a search, the ? is bound to searchQuery.
+const tsquery = "to_tsquery('english', ?)"
+
+// rank is the Postgres search rank, rounded like objects.RankKey so cursors compare exactly.
+const rank = "round(ts_rank(search, " + tsquery + ")::numeric, 9)"
+
+// rankedEvent is an Event listed by a full-text search.
+type rankedEvent struct {
+	objects.Event
+	Rank    float64
+	Snippet string
+}
+
+// search lists events matching a full-text search using the search column and its GIN index.
+func (p pg) search(ctx context.Context, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta, error) {
+	q := searchQuery(request.Query)
+	db := p.db.WithContext(ctx)
+
+	meta, err := count(filter(db.Model(&objects.Event{}), request, "ilike").Where("search @@ "+tsquery, q), request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := filter(db.Table("events").Limit(meta.Limit+1), request, "ilike").
+		Select(
+			"*, "+rank+" AS rank, ts_headline('english', concat_ws(' ', name, description, address), "+tsquery+
+				", 'StartSel=<mark>, StopSel=</mark>') AS snippet",
+			q,
+			q,
+		).
+		Where("search @@ "+tsquery, q)
+
+	if query, err = paginate(query, request, rank, q); err != nil {
+		return nil, nil, err
 	}
 
-	query := p.db.WithContext(ctx).Limit(limit)
+	var rows []*rankedEvent
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
 
+	list := make([]*objects.Event, 0, len(rows))
+	for _, row := range rows {
+		event := row.Event
+		event.Match = &objects.SearchMatch{Rank: row.Rank, Snippet: row.Snippet}
+
+		list = append(list, &event)
+	}
+
+	return page(list, request, meta), meta, nil
+}
+
+// searchQuery turns a search into a Postgres tsquery matching every word as a prefix.
+func searchQuery(q string) string {
+	terms := objects.SearchTerms(q)
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// count starts the ListMeta of a list, counting all events matching the filtered query unless skipped.
+func count(query *gorm.DB, request objects.ListRequest) (*objects.ListMeta, error) {
+	meta := &objects.ListMeta{Limit: request.PageLimit()}
+
+	if !request.SkipTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+
+		meta.Total = &total
+	}
+
+	return meta, nil
+}
+
+// paginate positions the query after the paging ID or cursor and orders it by the sort.
+// Sorting by relevance orders by the rank alias, so rankSQL and its args have to be given.
+func paginate(query *gorm.DB, request objects.ListRequest, rankSQL string, rankArgs ...interface{}) (*gorm.DB, error) {
 	if request.After != "" {
 		query = query.Where("id > ?", request.After)
 	}
 
-	if request.Name != "" {
-		query = query.Where("name ilike ?", "%"+request.Name+"%")
+	column, order := sortColumns[request.Sort.Field], sortColumns[request.Sort.Field]
+	switch {
+	case request.Sort.Field == objects.SortByRelevance && rankSQL != "":
+		column, order = rankSQL, "rank"
+	case column == "" || request.Sort.Field == objects.SortByRelevance:
+		column, order = "id", "id"
 	}
 
-	list := make([]*objects.Event, 0, limit)
+	direction, comparison := "asc", ">"
+	if request.Sort.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	if cursor := request.Cursor; cursor != nil {
+		var key interface{} = cursor.Key
+
+		switch {
+		case cursor.Sort.IsTime():
+			t, err := objects.KeyTime(cursor.Key)
+			if err != nil {
+				return nil, errors.ErrInvalidCursor
+			}
 
-	err := query.Order("id").Find(&list).Error
+			key = t
+		case cursor.Sort.Field == objects.SortByRelevance:
+			f, err := strconv.ParseFloat(cursor.Key, 64)
+			if err != nil {
+				return nil, errors.ErrInvalidCursor
+			}
 
-	return list, err
+			key = f
+		}
+
+		if column == "id" {
+			query = query.Where("id "+comparison+" ?", cursor.ID)
+		} else {
+			args := []interface{}{key, cursor.ID}
+			if column == rankSQL {
+				args = append(append([]interface{}{}, rankArgs...), args...)
+			}
+
+			query = query.Where("("+column+", id) "+comparison+" (?, ?)", args...)
+		}
+	}
+
+	return query.Order(order + " " + direction).Order("id " + direction), nil
+}
+
+// page trims a list fetched with one extra event to the limit and fills in the paging details of meta.
+func page(list []*objects.Event, request objects.ListRequest, meta *objects.ListMeta) []*objects.Event {
+	if len(list) <= meta.Limit {
+		return list
+	}
+
+	list = list[:meta.Limit]
+	meta.HasMore = true
+	meta.NextCursor = objects.CursorAfter(list[len(list)-1], request.Sort).Encode()
+
+	return list
+}
+
+// sortColumns maps the sortable fields to their columns.
+var sortColumns = map[objects.SortField]string{
+	objects.SortByID:        "id",
+	objects.SortByStart:     "start",
+	objects.SortByEnd:       `"end"`,
+	objects.SortByName:      "name",
+	objects.SortByCreatedAt: "created_at",
+}
+
+// filter adds the optional ListRequest filters to the query.
+func filter(query *gorm.DB, request objects.ListRequest, like string) *gorm.DB {
+	if request.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	for column, value := range map[string]string{
+		"name":        request.Name,
+		"address":     request.Address,
+		"description": request.Description,
+	} {
+		if value != "" {
+			query = query.Where(column+" "+like+` ? ESCAPE '\'`, likeContains(value))
+		}
+	}
+
+	if len(request.Status) > 0 {
+		query = query.Where("status IN ?", request.Status)
+	}
+
+	if !request.From.IsZero() {
+		query = query.Where(`"end" > ?`, request.From)
+	}
+
+	if !request.To.IsZero() {
+		query = query.Where("start < ?", request.To)
+	}
+
+	for clause, value := range map[string]time.Time{
+		"created_at >= ?": request.CreatedAfter,
+		"created_at <= ?": request.CreatedBefore,
+		"updated_at >= ?": request.UpdatedAfter,
+		"updated_at <= ?": request.UpdatedBefore,
+	} {
+		if !value.IsZero() {
+			query = query.Where(clause, value)
+		}
+	}
+
+	return query
+}
+
+// likeEscaper escapes the wildcards of like patterns, matched with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likeContains returns the like pattern of the values containing value, which is matched literally
+// like the memory store's substring matching.
+func likeContains(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
 }
 
 func (p pg) Create(ctx context.Context, request objects.CreateRequest) error {
@@ -82,61 +422,803 @@ func (p pg) Create(ctx context.Context, request objects.CreateRequest) error {
 
 	event := request.Event
 	event.ID = GenerateUniqueID()
-	event.Status = objects.Original
+	event.Status = initialStatus(event.Status)
+	event.Version = 1
 	event.CreatedAt = p.db.NowFunc()
 
-	return p.db.WithContext(ctx).Create(event).Error
+	return p.audited(ctx, "", objects.OpCreate, func(tx pg, entry *objects.HistoryEntry) error {
+		entry.EventID = event.ID
+
+		err := tx.db.Create(event).Error
+		if uidExists(err) {
+			return errors.ErrEventUIDExists
+		}
+
+		return err
+	})
 }
 
 func (p pg) Update(ctx context.Context, request objects.UpdateRequest) error {
-	event := &objects.Event{
-		ID:          request.ID,
-		Name:        request.Name,
-		Description: request.Description,
-		Website:     request.Website,
-		Address:     request.Address,
-		PhoneNumber: request.PhoneNumber,
-		UpdatedAt:   p.db.NowFunc(),
-	}
-
-	return p.db.WithContext(ctx).Model(event).Select(
-		"name",
-		"description",
-		"website",
-		"address",
-		"phone_number",
-		"updated_at",
-	).Updates(event).Error
+	return p.update(ctx, objects.OpUpdate, request.ID, request.Version, map[string]interface{}{
+		"name":         request.Name,
+		"description":  request.Description,
+		"website":      request.Website,
+		"address":      request.Address,
+		"phone_number": request.PhoneNumber,
+		"updated_at":   p.db.NowFunc(),
+	})
 }
 
-func (p pg) Cancel(ctx context.Context, request objects.CancelRequest) error {
-	event := &objects.Event{
-		ID:         request.ID,
-		Status:     objects.Canceled,
-		CanceledAt: p.db.NowFunc(),
+func (p pg) Patch(ctx context.Context, request objects.PatchRequest) error {
+	values := map[string]interface{}{}
+
+	for column, field := range map[string]objects.PatchString{
+		"name":         request.Name,
+		"description":  request.Description,
+		"website":      request.Website,
+		"address":      request.Address,
+		"phone_number": request.PhoneNumber,
+	} {
+		if field.Set {
+			values[column] = field.String()
+		}
 	}
 
-	return p.db.WithContext(ctx).Model(event).Select("status", "canceled_at").Updates(event).Error
+	if len(values) == 0 {
+		return nil
+	}
+
+	values["updated_at"] = p.db.NowFunc()
+
+	return p.update(ctx, objects.OpPatch, request.ID, request.Version, values)
+}
+
+func (p pg) Cancel(ctx context.Context, request objects.CancelRequest) error {
+	return p.transition(ctx, objects.OpCancel, request.ID, request.Version, objects.Canceled, map[string]interface{}{
+		"previous_status": gorm.Expr("status"),
+		"canceled_at":     p.db.NowFunc(),
+	})
 }
 
 func (p pg) Reschedule(ctx context.Context, request objects.RescheduleRequest) error {
-	event := &objects.Event{
-		ID:            request.ID,
-		TimeSlot:      request.NewTimeSlot,
-		Status:        objects.Rescheduled,
-		RescheduledAt: p.db.NowFunc(),
+	if request.NewTimeSlot == nil {
+		return errors.ErrEventTimingIsRequired
 	}
 
-	return p.db.WithContext(ctx).Model(event).Select(
-		"status",
-		"start_time",
-		"end_time",
-		"rescheduled_at",
-	).Updates(event).Error
+	return p.transition(ctx, objects.OpReschedule, request.ID, request.Version, objects.Rescheduled, map[string]interface{}{
+		"start":          request.NewTimeSlot.Start,
+		"end":            request.NewTimeSlot.End,
+		"rescheduled_at": p.db.NowFunc(),
+	})
+}
+
+func (p pg) Transition(ctx context.Context, request objects.TransitionRequest) error {
+	if !transitionable(request.Status) {
+		return errors.ErrInvalidStatus
+	}
+
+	return p.transition(ctx, objects.OpTransition, request.ID, request.Version, request.Status, map[string]interface{}{})
+}
+
+func (p pg) Uncancel(ctx context.Context, request objects.UncancelRequest) error {
+	// Restore the previous status when it's one that could have been canceled.
+	var restorable []interface{}
+	for _, status := range objects.TransitionsTo(objects.Canceled) {
+		restorable = append(restorable, status)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(restorable)), ", ")
+	status := gorm.Expr(
+		"CASE WHEN previous_status IN ("+placeholders+") THEN previous_status ELSE ? END",
+		append(restorable, objects.Original)...,
+	)
+
+	values := map[string]interface{}{
+		"status":          status,
+		"previous_status": "",
+		"canceled_at":     time.Time{},
+	}
+
+	return p.update(ctx, objects.OpUncancel, request.ID, request.Version, values, objects.Canceled)
 }
 
 func (p pg) Delete(ctx context.Context, request objects.DeleteRequest) error {
 	event := &objects.Event{ID: request.ID}
 
-	return p.db.WithContext(ctx).Model(event).Delete(event).Error
+	return p.audited(ctx, request.ID, objects.OpDelete, func(tx pg, _ *objects.HistoryEntry) error {
+		query := tx.db.Model(event)
+		if request.Version != 0 {
+			query = query.Where("version = ?", request.Version)
+		}
+
+		// Events are soft deleted, and their occurrences are kept in case they're restored.
+		result := query.Delete(event)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return orPreconditionFailed(tx.missingOrStale(ctx, request.ID, request.Version))
+		}
+
+		return nil
+	})
+}
+
+func (p pg) Restore(ctx context.Context, request objects.RestoreRequest) error {
+	return p.audited(ctx, request.ID, objects.OpRestore, func(tx pg, _ *objects.HistoryEntry) error {
+		deleted := &objects.Event{}
+
+		err := tx.locking(tx.db.Unscoped()).Take(deleted, "id = ? AND deleted_at IS NOT NULL", request.ID).Error
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrEventNotFound
+		} else if err != nil {
+			return err
+		}
+
+		if request.Version != 0 && deleted.Version != request.Version {
+			return errors.ErrPreconditionFailed
+		}
+
+		// Another Event may have taken the UID of the deleted one meanwhile.
+		err = tx.db.Unscoped().Model(&objects.Event{ID: request.ID}).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": p.db.NowFunc(),
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+		if uidExists(err) {
+			return errors.ErrEventUIDExists
+		}
+
+		return err
+	})
+}
+
+// uidExists reports whether err is from breaking the unique index on UIDs, in Postgres or SQLite.
+func uidExists(err error) bool {
+	switch err := err.(type) {
+	case *pgconn.PgError:
+		return err.Code == uniqueViolation && err.ConstraintName == uidIndex
+	case sqlite3.Error:
+		return err.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(err.Error(), "events.uid")
+	}
+
+	return false
+}
+
+func (p pg) Purge(ctx context.Context, request objects.PurgeRequest) (int64, error) {
+	var ids []string
+
+	err := p.inTx(ctx, func(tx pg) error {
+		// Lock the purged Events so they can't be restored meanwhile.
+		query := tx.db.Unscoped().Model(&objects.Event{})
+		if p.rowLocks {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+
+		err := query.Where("deleted_at < ?", request.DeletedBefore).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.db.Where("event_id IN ?", ids).Delete(&objects.Occurrence{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.db.Unscoped().Where("id IN ?", ids).Delete(&objects.Event{}).Error; err != nil {
+			return err
+		}
+
+		entries := make([]*objects.HistoryEntry, 0, len(ids))
+		for _, id := range ids {
+			entry := newEntry(ctx, id, objects.OpPurge)
+			record(entry, nil, nil, p.db.NowFunc().UTC())
+			entries = append(entries, entry)
+		}
+
+		return tx.publish(entries...)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (p pg) History(ctx context.Context, request objects.HistoryRequest) ([]*objects.HistoryEntry, *objects.ListMeta, error) {
+	entries := []*objects.HistoryEntry{}
+
+	err := p.db.WithContext(ctx).
+		Where("event_id = ? AND id > ?", request.ID, request.After).
+		Order("id").
+		Limit(request.PageLimit() + 1).
+		Find(&entries).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Events without history are only found while they exist.
+	if len(entries) == 0 && request.After == 0 {
+		if _, err := p.Get(ctx, objects.GetRequest{ID: request.ID}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	list, meta := historyPage(entries, request)
+
+	return list, meta, nil
+}
+
+// asOf gets the Event as it was recorded by the latest entry of its history up to request.AsOf.
+func (p pg) asOf(ctx context.Context, request objects.GetRequest) (*objects.Event, error) {
+	var entries []*objects.HistoryEntry
+
+	err := p.db.WithContext(ctx).
+		Where("event_id = ? AND at <= ?", request.ID, request.AsOf.UTC()).
+		Order("id DESC").
+		Limit(1).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return asOf(entries, request.AsOf)
+}
+
+// audited runs change in a transaction that records the Event with the id before and after it in
+// its history. Changes that create the Event pass no id and set the EventID of the entry instead.
+func (p pg) audited(ctx context.Context, id string, operation objects.HistoryOp, change func(tx pg, entry *objects.HistoryEntry) error) error {
+	return p.inTx(ctx, func(tx pg) error {
+		entry := newEntry(ctx, id, operation)
+
+		var before *objects.Event
+		if id != "" {
+			var err error
+			if before, err = tx.Get(ctx, objects.GetRequest{ID: id}); err != nil && err != errors.ErrEventNotFound {
+				return err
+			}
+		}
+
+		if err := change(tx, entry); err != nil {
+			return err
+		}
+
+		after, err := tx.Get(ctx, objects.GetRequest{ID: entry.EventID})
+		if err != nil && err != errors.ErrEventNotFound {
+			return err
+		}
+
+		record(entry, before, after, p.db.NowFunc().UTC())
+
+		return tx.publish(entry)
+	})
+}
+
+// publish appends the entries to the history and their changes to the outbox.
+func (p pg) publish(entries ...*objects.HistoryEntry) error {
+	if err := p.db.Create(&entries).Error; err != nil {
+		return err
+	}
+
+	messages := make([]*objects.OutboxMessage, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, newMessage(entry))
+	}
+
+	if err := p.db.Create(&messages).Error; err != nil {
+		return err
+	}
+
+	if !p.notifies {
+		p.notify(entries...)
+		return nil
+	}
+
+	// Notifications are only delivered once the transaction commits.
+	for _, entry := range entries {
+		if err := p.db.Exec("SELECT pg_notify(?, ?)", changesChannel, strconv.FormatInt(entry.ID, 10)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notify calls the Listeners with the entries, or holds them until the transaction of p is
+// committed.
+func (p pg) notify(entries ...*objects.HistoryEntry) {
+	if p.pending != nil {
+		*p.pending = append(*p.pending, entries...)
+		return
+	}
+
+	p.listeners.notify(entries...)
+}
+
+// inTx runs fn in a transaction, in which Get locks the Event when the database supports it, and
+// notifies the changes made in it once it's committed.
+func (p pg) inTx(ctx context.Context, fn func(tx pg) error) error {
+	var pending []*objects.HistoryEntry
+
+	err := p.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		tx := p
+		tx.db, tx.forUpdate, tx.pending = db, p.rowLocks, &pending
+
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.notify(pending...)
+
+	return nil
+}
+
+func (p pg) Listen(listener Listener) {
+	p.listeners.add(listener)
+}
+
+func (p pg) Batch(ctx context.Context, request objects.BatchRequest) ([]*objects.ItemResult, error) {
+	return batch(ctx, p, request)
+}
+
+// WithTx runs fn in a transaction in which Get locks the Event with SELECT ... FOR UPDATE.
+func (p pg) WithTx(ctx context.Context, fn func(tx EventStore) error) error {
+	return p.inTx(ctx, func(tx pg) error {
+		return fn(tx)
+	})
+}
+
+func (p pg) ListOccurrences(ctx context.Context, request objects.OccurrencesRequest) ([]*objects.Occurrence, error) {
+	event, err := p.recurring(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []*objects.Occurrence
+	if err := p.db.WithContext(ctx).Where("event_id = ?", request.ID).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+
+	return event.Occurrences(overrides, request.From, request.To)
+}
+
+func (p pg) ListOverrides(ctx context.Context, request objects.OverridesRequest) ([]*objects.Occurrence, error) {
+	overrides := []*objects.Occurrence{}
+	if len(request.IDs) == 0 {
+		return overrides, nil
+	}
+
+	err := p.db.WithContext(ctx).
+		Where("event_id IN ?", request.IDs).
+		Order("event_id, recurrence_id").
+		Find(&overrides).Error
+
+	return overrides, err
+}
+
+func (p pg) CancelOccurrence(ctx context.Context, request objects.CancelOccurrenceRequest) error {
+	return p.override(ctx, objects.OpCancelOccurrence, request.ID, request.RecurrenceID, objects.Canceled, func(occurrence *objects.Occurrence) {
+		occurrence.Status = objects.Canceled
+		occurrence.CanceledAt = p.db.NowFunc()
+	})
+}
+
+func (p pg) RescheduleOccurrence(ctx context.Context, request objects.RescheduleOccurrenceRequest) error {
+	if request.NewTimeSlot == nil {
+		return errors.ErrEventTimingIsRequired
+	}
+
+	return p.override(ctx, objects.OpRescheduleOccurrence, request.ID, request.RecurrenceID, objects.Rescheduled, func(occurrence *objects.Occurrence) {
+		occurrence.TimeSlot = request.NewTimeSlot
+		occurrence.Status = objects.Rescheduled
+		occurrence.RescheduledAt = p.db.NowFunc()
+	})
+}
+
+// recurring gets an event, which has to recur.
+func (p pg) recurring(ctx context.Context, id string) (*objects.Event, error) {
+	event, err := p.Get(ctx, objects.GetRequest{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.Recurring() {
+		return nil, errors.ErrEventNotRecurring
+	}
+
+	return event, nil
+}
+
+// override changes the stored override of one occurrence of a recurring event to the status,
+// starting from the plain occurrence when there's none yet, and bumps the event's version.
+func (p pg) override(ctx context.Context, operation objects.HistoryOp, id string, recurrenceID time.Time, to objects.EventStatus, change func(occurrence *objects.Occurrence)) error {
+	return p.audited(ctx, id, operation, func(tx pg, entry *objects.HistoryEntry) error {
+		event, err := tx.recurring(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !event.Status.Active() {
+			return errors.ErrInvalidTransition
+		}
+
+		recurrenceID = recurrenceID.UTC()
+		if !event.IsOccurrence(recurrenceID) {
+			return errors.ErrOccurrenceNotFound
+		}
+
+		occurrence := &objects.Occurrence{}
+
+		err = tx.db.Take(occurrence, "event_id = ? AND recurrence_id = ?", id, recurrenceID).Error
+		if err == gorm.ErrRecordNotFound {
+			occurrence = newOccurrence(event, recurrenceID)
+		} else if err != nil {
+			return err
+		}
+
+		if !occurrence.Status.CanTransition(to) {
+			return errors.ErrInvalidTransition
+		}
+
+		previous := *occurrence
+		change(occurrence)
+		occurrenceChange(entry, &previous, occurrence)
+
+		if err := tx.db.Save(occurrence).Error; err != nil {
+			return err
+		}
+
+		return tx.db.Model(&objects.Event{ID: id}).Updates(map[string]interface{}{
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": p.db.NowFunc(),
+		}).Error
+	})
+}
+
+// newOccurrence returns the occurrence of a recurring event starting at recurrenceID, as if it had no override.
+func newOccurrence(event *objects.Event, recurrenceID time.Time) *objects.Occurrence {
+	duration := event.TimeSlot.End.Sub(event.TimeSlot.Start)
+
+	return &objects.Occurrence{
+		EventID:      event.ID,
+		RecurrenceID: recurrenceID,
+		TimeSlot:     &objects.TimeSlot{Start: recurrenceID, End: recurrenceID.Add(duration)},
+		Status:       event.Status,
+	}
+}
+
+// transition writes values to the event and changes its status to the status to, if its
+// current status can change to it. See update.
+func (p pg) transition(ctx context.Context, operation objects.HistoryOp, id string, version int, to objects.EventStatus, values map[string]interface{}) error {
+	values["status"] = to
+
+	return p.update(ctx, operation, id, version, values, objects.TransitionsTo(to)...)
+}
+
+// update writes values to the event, bumps its version and records the operation in its
+// history. When version is non-zero the write only happens if it matches the stored version,
+// and when statuses are given only if the event has one of them.
+func (p pg) update(ctx context.Context, operation objects.HistoryOp, id string, version int, values map[string]interface{}, statuses ...objects.EventStatus) error {
+	values["version"] = gorm.Expr("version + 1")
+
+	return p.audited(ctx, id, operation, func(tx pg, _ *objects.HistoryEntry) error {
+		query := tx.db.Model(&objects.Event{ID: id}).Where("deleted_at IS NULL")
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+
+		if len(statuses) > 0 {
+			query = query.Where("status IN ?", statuses)
+		}
+
+		result := query.Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			if err := tx.missingOrStale(ctx, id, version); err != nil || len(statuses) == 0 {
+				return orPreconditionFailed(err)
+			}
+
+			return errors.ErrInvalidTransition
+		}
+
+		return nil
+	})
+}
+
+// missingOrStale explains why a write matched no rows when the event is missing or at another
+// version, and returns nil otherwise.
+func (p pg) missingOrStale(ctx context.Context, id string, version int) error {
+	event, err := p.Get(ctx, objects.GetRequest{ID: id})
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && event.Version != version {
+		return errors.ErrPreconditionFailed
+	}
+
+	return nil
+}
+
+// orPreconditionFailed is err, or ErrPreconditionFailed for a write that matched no rows for
+// no other reason, such as the event changing in between.
+func orPreconditionFailed(err error) error {
+	if err == nil {
+		return errors.ErrPreconditionFailed
+	}
+
+	return err
+}
+
+func (p pg) CreateFeed(ctx context.Context, request objects.CreateFeedRequest) error {
+	if request.Feed == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	request.Feed.Token = token
+
+	return p.db.WithContext(ctx).Create(request.Feed).Error
+}
+
+func (p pg) GetFeed(ctx context.Context, request objects.GetFeedRequest) (*objects.Feed, error) {
+	feed := &objects.Feed{}
+
+	err := p.db.WithContext(ctx).Take(feed, "token = ?", request.Token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.ErrFeedNotFound
+	}
+
+	return feed, err
+}
+
+func (p pg) UpdateFeed(ctx context.Context, request objects.UpdateFeedRequest) error {
+	result := p.db.WithContext(ctx).Model(&objects.Feed{}).Where("token = ?", request.Token).Updates(map[string]interface{}{
+		"etag":        request.ETag,
+		"modified_at": request.ModifiedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrFeedNotFound
+	}
+
+	return nil
+}
+
+func (p pg) RevokeFeed(ctx context.Context, request objects.RevokeFeedRequest) error {
+	result := p.db.WithContext(ctx).Where("token = ?", request.Token).Delete(&objects.Feed{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrFeedNotFound
+	}
+
+	return nil
+}
+
+func (p pg) CreateWebhook(ctx context.Context, request objects.CreateWebhookRequest) error {
+	if request.Webhook == nil {
+		return errors.ErrObjectIsRequired
+	}
+
+	secret, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	webhook := request.Webhook
+	webhook.ID = GenerateUniqueID()
+	webhook.Secret = secret
+
+	return p.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (p pg) GetWebhook(ctx context.Context, request objects.GetWebhookRequest) (*objects.Webhook, error) {
+	webhook := &objects.Webhook{}
+
+	err := p.db.WithContext(ctx).Take(webhook, "id = ?", request.ID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.ErrWebhookNotFound
+	}
+
+	return webhook, err
+}
+
+func (p pg) ListWebhooks(ctx context.Context) ([]*objects.Webhook, error) {
+	webhooks := []*objects.Webhook{}
+
+	return webhooks, p.db.WithContext(ctx).Order("id").Find(&webhooks).Error
+}
+
+func (p pg) UpdateWebhook(ctx context.Context, request objects.UpdateWebhookRequest) error {
+	result := p.db.WithContext(ctx).Model(&objects.Webhook{ID: request.ID}).Updates(map[string]interface{}{
+		"url":        request.URL,
+		"operations": request.Operations,
+		"updated_at": p.db.NowFunc(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (p pg) DeleteWebhook(ctx context.Context, request objects.DeleteWebhookRequest) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", request.ID).Delete(&objects.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.ErrWebhookNotFound
+		}
+
+		return tx.Where("webhook_id = ?", request.ID).Delete(&objects.Delivery{}).Error
+	})
+}
+
+func (p pg) ListDeliveries(ctx context.Context, request objects.ListDeliveriesRequest) ([]*objects.Delivery, error) {
+	if _, err := p.GetWebhook(ctx, objects.GetWebhookRequest{ID: request.WebhookID}); err != nil {
+		return nil, err
+	}
+
+	query := p.db.WithContext(ctx).Where("webhook_id = ?", request.WebhookID)
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	deliveries := []*objects.Delivery{}
+
+	return deliveries, query.Order("id DESC").Limit(request.PageLimit()).Find(&deliveries).Error
+}
+
+func (p pg) RetryDelivery(ctx context.Context, request objects.RetryDeliveryRequest) error {
+	result := p.db.WithContext(ctx).Model(&objects.Delivery{}).
+		Where("id = ? AND webhook_id = ? AND status = ?", request.ID, request.WebhookID, objects.DeliveryDead).
+		Updates(map[string]interface{}{
+			"status":          objects.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": p.db.NowFunc().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+func (p pg) ClaimDeliveries(ctx context.Context, request objects.ClaimDeliveriesRequest) ([]*objects.Delivery, error) {
+	var claimed []*objects.Delivery
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := p.db.NowFunc().UTC()
+
+		var messages []*objects.OutboxMessage
+		if err := p.skipLocked(tx).Order("id").Limit(request.Limit).Find(&messages).Error; err != nil {
+			return err
+		}
+
+		if len(messages) > 0 {
+			var webhooks []*objects.Webhook
+			if err := tx.Find(&webhooks).Error; err != nil {
+				return err
+			}
+
+			if deliveries := fanOut(messages, webhooks, now); len(deliveries) > 0 {
+				if err := tx.Create(&deliveries).Error; err != nil {
+					return err
+				}
+			}
+
+			ids := make([]int64, 0, len(messages))
+			for _, message := range messages {
+				ids = append(ids, message.ID)
+			}
+
+			if err := tx.Where("id IN ?", ids).Delete(&objects.OutboxMessage{}).Error; err != nil {
+				return err
+			}
+		}
+
+		err := p.skipLocked(tx).
+			Where("status = ? AND next_attempt_at <= ?", objects.DeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(request.Limit).
+			Find(&claimed).Error
+		if err != nil || len(claimed) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(claimed))
+		webhookIDs := make([]string, 0, len(claimed))
+		for _, delivery := range claimed {
+			ids = append(ids, delivery.ID)
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+
+		// Others skip the claimed Deliveries until the lease runs out.
+		err = tx.Model(&objects.Delivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(request.Lease)).Error
+		if err != nil {
+			return err
+		}
+
+		var webhooks []*objects.Webhook
+		if err := tx.Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+			return err
+		}
+
+		for _, delivery := range claimed {
+			for _, webhook := range webhooks {
+				if webhook.ID == delivery.WebhookID {
+					delivery.Webhook = webhook
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func (p pg) CompleteDelivery(ctx context.Context, request objects.CompleteDeliveryRequest) error {
+	delivery := request.Delivery
+
+	return p.db.WithContext(ctx).Model(&objects.Delivery{ID: delivery.ID}).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_attempt_at": delivery.NextAttemptAt.UTC(),
+		"last_code":       delivery.LastCode,
+		"last_error":      delivery.LastError,
+		"delivered_at":    delivery.DeliveredAt.UTC(),
+	}).Error
+}
+
+// skipLocked locks the rows read by the query, skipping rows locked by others, when the database
+// supports it.
+func (p pg) skipLocked(query *gorm.DB) *gorm.DB {
+	if p.rowLocks {
+		return query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+
+	return query
+}
+
+func (p pg) Close() error {
+	if p.stopListening != nil {
+		p.stopListening()
+	}
+
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
 }
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/theantichris/events-api/objects"
+)
+
+// snippetWords is the most words in a fallback search snippet, like the ts_headline default.
+const snippetWords = 35
+
+// searchFields are the fields of the fallback search, weighted like the Postgres A, B and C weights.
+var searchFields = []struct {
+	value  func(event *objects.Event) string
+	weight float64
+}{
+	{func(event *objects.Event) string { return event.Name }, 1.0},
+	{func(event *objects.Event) string { return event.Description }, 0.4},
+	{func(event *objects.Event) string { return event.Address }, 0.2},
+}
+
+// match is the fallback full-text search for stores without Postgres. Every term has to prefix
+// a word in one of the searched fields, and the rank sums the weights of the matching words.
+func match(event *objects.Event, terms []string) (*objects.SearchMatch, bool) {
+	var rank float64
+	var text []string
+
+	for _, term := range terms {
+		found := false
+
+		for _, field := range searchFields {
+			for _, word := range searchWords(field.value(event)) {
+				if strings.HasPrefix(word, term) {
+					rank += field.weight
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			return nil, false
+		}
+	}
+
+	for _, field := range searchFields {
+		if value := field.value(event); value != "" {
+			text = append(text, value)
+		}
+	}
+
+	return &objects.SearchMatch{Rank: rank, Snippet: snippet(strings.Join(text, " "), terms)}, true
+}
+
+// snippet highlights the words of text that match a term, starting shortly before the first match.
+func snippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := -1
+
+	for i, word := range words {
+		for _, term := range terms {
+			if strings.HasPrefix(searchWord(word), term) {
+				words[i] = "<mark>" + word + "</mark>"
+
+				if first == -1 {
+					first = i
+				}
+
+				break
+			}
+		}
+	}
+
+	start := 0
+	if first > 5 {
+		start = first - 5
+	}
+
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	return strings.Join(words[start:end], " ")
+}
+
+// searchWords splits text into the lowercase words that search terms are matched against.
+func searchWords(text string) []string {
+	return objects.SearchTerms(text)
+}
+
+// searchWord normalizes a single word of text the same way as searchWords.
+func searchWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}))
+}
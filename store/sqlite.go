@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theantichris/events-api/objects"
+	"gorm.io/driver/sqlite"
+)
+
+// sqliteStore shares the gorm implementation of pg and only overrides Postgres specific queries.
+type sqliteStore struct {
+	pg
+}
+
+// NewSQLiteEventStore creates and returns a SQLite implementation of an EventStore.
+// The path is the SQLite database file e.g. "events.db".
+func NewSQLiteEventStore(path string) (EventStore, error) {
+	db, err := open(sqlite.Open(path), 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the database: %w", err)
+	}
+
+	// SQLite only allows a single writer, so serialize access through one connection.
+	sqlDB, err := db.DB()
+	if err != nil {
+		closeDB(db)
+		return nil, fmt.Errorf("unable to open the database: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	// SQLite schemas aren't versioned like Postgres ones, AutoMigrate only adds missing tables and
+	// columns, so changes to existing ones in store/migrations must be made by hand here.
+	if err := db.AutoMigrate(&objects.Event{}, &objects.Occurrence{}, &objects.Feed{}, &objects.HistoryEntry{},
+		&objects.Webhook{}, &objects.OutboxMessage{}, &objects.Delivery{}); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("unable to migrate database: %w", err)
+	}
+
+	// Like the Postgres migration, UIDs only have to be unique among the Events that aren't deleted.
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + uidIndex + " ON events (uid) WHERE uid <> '' AND deleted_at IS NULL").Error; err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("unable to migrate database: %w", err)
+	}
+
+	return &sqliteStore{pg{db: db, listeners: &listeners{}}}, nil
+}
+
+// WithTx runs fn in a transaction. SQLite has no row locks, but the single connection already
+// keeps any other reads and writes out until the transaction ends.
+func (s sqliteStore) WithTx(ctx context.Context, fn func(tx EventStore) error) error {
+	return s.inTx(ctx, func(tx pg) error {
+		return fn(sqliteStore{tx})
+	})
+}
+
+func (s sqliteStore) Batch(ctx context.Context, request objects.BatchRequest) ([]*objects.ItemResult, error) {
+	return batch(ctx, s, request)
+}
+
+// List uses like, which SQLite matches case-insensitively, in place of ilike. Without a full-text
+// index, searches narrow down candidates with like and rank them the same way as the memory store.
+func (s sqliteStore) List(ctx context.Context, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta, error) {
+	if request.Query == "" {
+		return list(s.db.WithContext(ctx), request, "like")
+	}
+
+	query := filter(s.db.WithContext(ctx), request, "like")
+
+	for _, term := range objects.SearchTerms(request.Query) {
+		pattern := likeContains(term)
+		query = query.Where(`(name like ? ESCAPE '\' or description like ? ESCAPE '\' or address like ? ESCAPE '\')`, pattern, pattern, pattern)
+	}
+
+	var candidates []*objects.Event
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, nil, err
+	}
+
+	list, meta := listEvents(candidates, request)
+
+	return list, meta, nil
+}
@@ -2,8 +2,11 @@ package store
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/theantichris/events-api/objects"
@@ -12,12 +15,82 @@ import (
 // EventStore defines the database interactions for storing Events.
 type EventStore interface {
 	Get(ctx context.Context, request objects.GetRequest) (*objects.Event, error)
-	List(ctx context.Context, request objects.ListRequest) ([]*objects.Event, error)
+	List(ctx context.Context, request objects.ListRequest) ([]*objects.Event, *objects.ListMeta, error)
 	Create(ctx context.Context, request objects.CreateRequest) error
 	Update(ctx context.Context, request objects.UpdateRequest) error
+	Patch(ctx context.Context, request objects.PatchRequest) error
 	Cancel(ctx context.Context, request objects.CancelRequest) error
 	Reschedule(ctx context.Context, request objects.RescheduleRequest) error
+	Transition(ctx context.Context, request objects.TransitionRequest) error
+	Uncancel(ctx context.Context, request objects.UncancelRequest) error
+
+	// Delete moves an Event to the trash, where only List with Deleted set finds it, until it's
+	// brought back by Restore or permanently removed by Purge, which returns how many it removed.
 	Delete(ctx context.Context, request objects.DeleteRequest) error
+	Restore(ctx context.Context, request objects.RestoreRequest) error
+	Purge(ctx context.Context, request objects.PurgeRequest) (int64, error)
+
+	// History lists the changes recorded for an Event, oldest first. Every change of an Event is
+	// recorded with the Actor of its ctx, in the same transaction as the change.
+	History(ctx context.Context, request objects.HistoryRequest) ([]*objects.HistoryEntry, *objects.ListMeta, error)
+
+	// Listen calls the listener with every change of an Event made through the store from now on,
+	// once the transaction of the change is committed.
+	Listen(listener Listener)
+
+	// WithTx runs fn with a store whose reads and writes form one transaction, which is committed
+	// when fn returns nil and rolled back otherwise. An Event read with Get inside the transaction
+	// can't be changed by others until it ends, so fn can check an Event and then change it.
+	WithTx(ctx context.Context, fn func(tx EventStore) error) error
+
+	// Batch applies the operations of the request in order and reports the outcome of each.
+	Batch(ctx context.Context, request objects.BatchRequest) ([]*objects.ItemResult, error)
+
+	ListOccurrences(ctx context.Context, request objects.OccurrencesRequest) ([]*objects.Occurrence, error)
+	ListOverrides(ctx context.Context, request objects.OverridesRequest) ([]*objects.Occurrence, error)
+	CancelOccurrence(ctx context.Context, request objects.CancelOccurrenceRequest) error
+	RescheduleOccurrence(ctx context.Context, request objects.RescheduleOccurrenceRequest) error
+
+	CreateFeed(ctx context.Context, request objects.CreateFeedRequest) error
+	GetFeed(ctx context.Context, request objects.GetFeedRequest) (*objects.Feed, error)
+	UpdateFeed(ctx context.Context, request objects.UpdateFeedRequest) error
+	RevokeFeed(ctx context.Context, request objects.RevokeFeedRequest) error
+
+	CreateWebhook(ctx context.Context, request objects.CreateWebhookRequest) error
+	GetWebhook(ctx context.Context, request objects.GetWebhookRequest) (*objects.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*objects.Webhook, error)
+	UpdateWebhook(ctx context.Context, request objects.UpdateWebhookRequest) error
+	DeleteWebhook(ctx context.Context, request objects.DeleteWebhookRequest) error
+	ListDeliveries(ctx context.Context, request objects.ListDeliveriesRequest) ([]*objects.Delivery, error)
+	RetryDelivery(ctx context.Context, request objects.RetryDeliveryRequest) error
+
+	// ClaimDeliveries turns the changes in the outbox, which every change of an Event is written to
+	// in its transaction, into a Delivery to each Webhook that wants it, and then claims the pending
+	// Deliveries that are due along with their Webhook. CompleteDelivery saves how an attempt went.
+	ClaimDeliveries(ctx context.Context, request objects.ClaimDeliveriesRequest) ([]*objects.Delivery, error)
+	CompleteDelivery(ctx context.Context, request objects.CompleteDeliveryRequest) error
+
+	// Close releases the store's resources, such as its connection pool.
+	Close() error
+}
+
+// Connection string schemes that select an EventStore other than Postgres.
+const (
+	MemoryScheme = "memory://"
+	SQLiteScheme = "sqlite://"
+)
+
+// NewEventStore creates and returns an EventStore based on the scheme of the connection string.
+// Connecting to Postgres is retried until retryWindow has elapsed.
+func NewEventStore(conn string, retryWindow time.Duration) (EventStore, error) {
+	switch {
+	case strings.HasPrefix(conn, MemoryScheme):
+		return NewMemoryEventStore(), nil
+	case strings.HasPrefix(conn, SQLiteScheme):
+		return NewSQLiteEventStore(strings.TrimPrefix(conn, SQLiteScheme))
+	default:
+		return NewPostgresEventStore(conn, retryWindow)
+	}
 }
 
 func init() {
@@ -36,3 +109,13 @@ func GenerateUniqueID() string {
 
 	return fmt.Sprintf("%010v-%010v-%s", now.Unix(), now.Nanosecond(), string(word))
 }
+
+// GenerateToken creates an unguessable token for a Feed.
+func GenerateToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theantichris/events-api/errors"
+	"github.com/theantichris/events-api/objects"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// stores returns every EventStore that runs without a server, each fresh.
+func stores(t *testing.T) map[string]EventStore {
+	sqlite, err := NewEventStore(SQLiteScheme+filepath.Join(t.TempDir(), "events.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlite.Close() })
+
+	return map[string]EventStore{
+		"Memory": NewMemoryEventStore(),
+		"SQLite": sqlite,
+	}
+}
+
+func newEvent(name string) *objects.Event {
+	start := time.Date(2030, 1, 1, 18, 0, 0, 0, time.UTC)
+
+	return &objects.Event{Name: name, TimeSlot: &objects.TimeSlot{Start: start, End: start.Add(2 * time.Hour)}}
+}
+
+func TestEventStore(t *testing.T) {
+	for name, st := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			event := newEvent("Launch")
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: event}))
+			assert.NotEmpty(t, event.ID)
+			assert.Equal(t, 1, event.Version)
+
+			got, err := st.Get(ctx, objects.GetRequest{ID: event.ID})
+			assert.Nil(t, err)
+			assert.Equal(t, "Launch", got.Name)
+			assert.Equal(t, objects.Original, got.Status)
+
+			assert.Nil(t, st.Update(ctx, objects.UpdateRequest{ID: event.ID, Name: "Relaunch", Version: 1}))
+			assert.Equal(t, errors.ErrPreconditionFailed, st.Update(ctx, objects.UpdateRequest{ID: event.ID, Name: "Stale", Version: 1}))
+
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: newEvent("Other")}))
+
+			// Like wildcards in filters are matched literally.
+			events, meta, err := st.List(ctx, objects.ListRequest{Name: "relaunch"})
+			assert.Nil(t, err)
+			assert.Len(t, events, 1)
+			assert.Equal(t, int64(1), *meta.Total)
+
+			events, _, err = st.List(ctx, objects.ListRequest{Name: "%"})
+			assert.Nil(t, err)
+			assert.Empty(t, events)
+
+			assert.Nil(t, st.Delete(ctx, objects.DeleteRequest{ID: event.ID}))
+			_, err = st.Get(ctx, objects.GetRequest{ID: event.ID})
+			assert.Equal(t, errors.ErrEventNotFound, err)
+
+			events, _, err = st.List(ctx, objects.ListRequest{Deleted: true})
+			assert.Nil(t, err)
+			assert.Len(t, events, 1)
+
+			assert.Nil(t, st.Restore(ctx, objects.RestoreRequest{ID: event.ID}))
+
+			history, _, err := st.History(ctx, objects.HistoryRequest{ID: event.ID})
+			assert.Nil(t, err)
+
+			var operations []objects.HistoryOp
+			for _, entry := range history {
+				operations = append(operations, entry.Operation)
+			}
+			assert.Equal(t, []objects.HistoryOp{objects.OpCreate, objects.OpUpdate, objects.OpDelete, objects.OpRestore}, operations)
+
+			feed := &objects.Feed{Name: "All"}
+			assert.Nil(t, st.CreateFeed(ctx, objects.CreateFeedRequest{Feed: feed}))
+			assert.Nil(t, st.RevokeFeed(ctx, objects.RevokeFeedRequest{Token: feed.Token}))
+			_, err = st.GetFeed(ctx, objects.GetFeedRequest{Token: feed.Token})
+			assert.Equal(t, errors.ErrFeedNotFound, err)
+		})
+	}
+}
+
+func TestUID(t *testing.T) {
+	for name, st := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			imported := newEvent("Imported")
+			imported.UID = "jazz@example.com"
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: imported}))
+
+			duplicate := newEvent("Duplicate")
+			duplicate.UID = imported.UID
+			assert.Equal(t, errors.ErrEventUIDExists, st.Create(ctx, objects.CreateRequest{Event: duplicate}))
+
+			// Events without a UID don't conflict.
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: newEvent("First")}))
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: newEvent("Second")}))
+
+			// Deleted Events give up their UID until they're restored.
+			assert.Nil(t, st.Delete(ctx, objects.DeleteRequest{ID: imported.ID}))
+
+			reimported := newEvent("Reimported")
+			reimported.UID = imported.UID
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: reimported}))
+
+			assert.Equal(t, errors.ErrEventUIDExists, st.Restore(ctx, objects.RestoreRequest{ID: imported.ID}))
+
+			got, err := st.Get(ctx, objects.GetRequest{UID: imported.UID})
+			assert.Nil(t, err)
+			assert.Equal(t, reimported.ID, got.ID)
+		})
+	}
+}
+
+func TestWithTx(t *testing.T) {
+	for name, st := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			event := newEvent("Launch")
+			assert.Nil(t, st.Create(ctx, objects.CreateRequest{Event: event}))
+
+			var notified []objects.HistoryOp
+			st.Listen(func(entry *objects.HistoryEntry) {
+				notified = append(notified, entry.Operation)
+			})
+
+			// A failed transaction leaves nothing behind, and notifies nothing.
+			err := st.WithTx(ctx, func(tx EventStore) error {
+				assert.Nil(t, tx.Create(ctx, objects.CreateRequest{Event: newEvent("Rolled back")}))
+				assert.Nil(t, tx.Cancel(ctx, objects.CancelRequest{ID: event.ID}))
+
+				// The transaction reads its own changes.
+				canceled, err := tx.Get(ctx, objects.GetRequest{ID: event.ID})
+				assert.Nil(t, err)
+				assert.Equal(t, objects.Canceled, canceled.Status)
+
+				return errors.ErrInvalidTransition
+			})
+			assert.Equal(t, errors.ErrInvalidTransition, err)
+			assert.Empty(t, notified)
+
+			got, err := st.Get(ctx, objects.GetRequest{ID: event.ID})
+			assert.Nil(t, err)
+			assert.Equal(t, objects.Original, got.Status)
+			assert.Equal(t, 1, got.Version)
+
+			events, _, err := st.List(ctx, objects.ListRequest{})
+			assert.Nil(t, err)
+			assert.Len(t, events, 1)
+
+			history, _, err := st.History(ctx, objects.HistoryRequest{ID: event.ID})
+			assert.Nil(t, err)
+			assert.Len(t, history, 1)
+
+			err = st.WithTx(ctx, func(tx EventStore) error {
+				return tx.Cancel(ctx, objects.CancelRequest{ID: event.ID, Version: 1})
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, []objects.HistoryOp{objects.OpCancel}, notified)
+
+			got, err = st.Get(ctx, objects.GetRequest{ID: event.ID})
+			assert.Nil(t, err)
+			assert.Equal(t, objects.Canceled, got.Status)
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	// Nothing passed on before the connection was lost, and changes 1 and 2 were made meanwhile.
+	passed := &changes{}
+	passed.start(nil)
+	assert.Equal(t, int64(0), passed.from())
+	assert.True(t, passed.add(1))
+	assert.True(t, passed.add(2))
+	assert.False(t, passed.add(2))
+
+	// Change 5 was passed on first, and change 4 committed late while reconnecting.
+	passed = &changes{}
+	passed.start([]int64{3, 2, 1})
+	assert.False(t, passed.add(3))
+	assert.True(t, passed.add(5))
+	assert.Less(t, passed.from(), int64(4))
+	assert.True(t, passed.add(4))
+	assert.False(t, passed.add(5))
+
+	// Only the changes within the window are remembered, but older ones are still passed on.
+	passed.add(5 + replayWindow)
+	assert.Equal(t, int64(5), passed.from())
+	assert.Len(t, passed.seen, 1)
+	assert.True(t, passed.add(2))
+}
+
+// unreachable is a Dialector whose database can't be connected to, which keeps the connection
+// pools it opens.
+type unreachable struct {
+	sqlite.Dialector
+	pools []*sql.DB
+}
+
+func (u *unreachable) Initialize(db *gorm.DB) error {
+	pool, err := sql.Open("sqlite3", filepath.Join(u.DSN, "missing", "events.db"))
+	if err != nil {
+		return err
+	}
+
+	u.pools = append(u.pools, pool)
+	db.ConnPool = pool
+
+	return nil
+}
+
+func TestOpen(t *testing.T) {
+	dialector := &unreachable{Dialector: sqlite.Dialector{DSN: t.TempDir()}}
+
+	// The first attempt is retried after minBackoff, and the second would end after the window.
+	started := time.Now()
+	_, err := open(dialector, minBackoff+minBackoff/2)
+	elapsed := time.Since(started)
+
+	assert.NotNil(t, err)
+	assert.Len(t, dialector.pools, 2)
+	assert.True(t, elapsed >= minBackoff && elapsed < 2*minBackoff, elapsed)
+
+	// The pools of the failed attempts are closed.
+	for _, pool := range dialector.pools {
+		assert.EqualError(t, pool.Ping(), "sql: database is closed")
+	}
+
+	_, err = open(dialector, 0)
+	assert.NotNil(t, err)
+	assert.Len(t, dialector.pools, 3)
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/theantichris/events-api/objects"
+)
+
+// newMessage returns the OutboxMessage that delivers a recorded change to the Webhooks.
+func newMessage(entry *objects.HistoryEntry) *objects.OutboxMessage {
+	payload, _ := json.Marshal(entry)
+
+	return &objects.OutboxMessage{
+		EventID:   entry.EventID,
+		Operation: entry.Operation,
+		Payload:   objects.RawJSON(payload),
+		CreatedAt: entry.At,
+	}
+}
+
+// fanOut returns a pending Delivery of each message to each of the Webhooks that wants it, leaving
+// out changes made before a Webhook was created.
+func fanOut(messages []*objects.OutboxMessage, webhooks []*objects.Webhook, now time.Time) []*objects.Delivery {
+	var deliveries []*objects.Delivery
+
+	for _, message := range messages {
+		for _, webhook := range webhooks {
+			if !webhook.Wants(message.Operation) || message.CreatedAt.Before(webhook.CreatedAt) {
+				continue
+			}
+
+			deliveries = append(deliveries, &objects.Delivery{
+				WebhookID:     webhook.ID,
+				MessageID:     message.ID,
+				EventID:       message.EventID,
+				Operation:     message.Operation,
+				Payload:       message.Payload,
+				Status:        objects.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	return deliveries
+}
@@ -0,0 +1,163 @@
+// Package stream fans the changes of Events out to the clients streaming them.
+package stream
+
+import (
+	"sync"
+
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// Message types, one for each kind of change streamed.
+const (
+	Created     = "created"
+	Updated     = "updated"
+	Canceled    = "canceled"
+	Rescheduled = "rescheduled"
+	Deleted     = "deleted"
+)
+
+// Message is a change of an Event sent to the clients streaming changes.
+type Message struct {
+	ID    int64 // the ID of the HistoryEntry of the change
+	Type  string
+	Event *objects.Event // after the change, or before it when deleted
+
+	before *objects.Event
+}
+
+// NewMessage returns the Message of the change recorded by the entry, or false when the change
+// isn't streamed.
+func NewMessage(entry *objects.HistoryEntry) (*Message, bool) {
+	message := &Message{ID: entry.ID, Event: entry.After.Event(), before: entry.Before.Event()}
+
+	switch entry.Operation {
+	case objects.OpCreate, objects.OpRestore:
+		message.Type = Created
+	case objects.OpCancel:
+		message.Type = Canceled
+	case objects.OpReschedule:
+		message.Type = Rescheduled
+	case objects.OpDelete:
+		message.Type = Deleted
+		message.Event = message.before
+	case objects.OpPurge:
+		// The Event was streamed as deleted when it was moved to the trash.
+		return nil, false
+	default:
+		message.Type = Updated
+	}
+
+	if message.Event == nil {
+		return nil, false
+	}
+
+	return message, true
+}
+
+// Matches reports whether the Event is listed by the request before or after the change, so
+// clients also learn of Events that stop matching.
+func (m *Message) Matches(request objects.ListRequest) bool {
+	return store.Matches(m.Event, request) || (m.before != nil && store.Matches(m.before, request))
+}
+
+// DefaultBufferSize is how many Messages a Broker keeps for clients resuming their stream.
+const DefaultBufferSize = 1000
+
+// subscriptionSize is how many Messages a Subscription holds before it's too far behind.
+const subscriptionSize = 64
+
+// Broker fans Messages out to Subscriptions, and keeps the latest for Subscriptions that resume
+// after one they received.
+type Broker struct {
+	mu            sync.Mutex
+	buffer        []*Message // oldest first
+	size          int
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewBroker creates and returns a Broker that keeps the latest size Messages.
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	return &Broker{size: size, subscriptions: map[*Subscription]struct{}{}}
+}
+
+// Publish sends the change recorded by the entry to the Subscriptions. It's a store.Listener.
+func (b *Broker) Publish(entry *objects.HistoryEntry) {
+	message, ok := NewMessage(entry)
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buffer = append(b.buffer, message)
+	if len(b.buffer) > b.size {
+		b.buffer = b.buffer[len(b.buffer)-b.size:]
+	}
+
+	for subscription := range b.subscriptions {
+		select {
+		case subscription.messages <- message:
+		default:
+			// Drop Subscriptions that fall behind rather than hold up the others, they can resume
+			// from the buffer.
+			b.remove(subscription)
+		}
+	}
+}
+
+// Subscribe returns a Subscription to the Messages published from now on, and the buffered
+// Messages after the one with lastID, when not zero. It reports false when that Message is no
+// longer buffered, so Messages in between were missed.
+func (b *Broker) Subscribe(lastID int64) (*Subscription, []*Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscription := &Subscription{broker: b, messages: make(chan *Message, subscriptionSize)}
+	b.subscriptions[subscription] = struct{}{}
+
+	if lastID == 0 {
+		return subscription, nil, true
+	}
+
+	for i, message := range b.buffer {
+		if message.ID == lastID {
+			return subscription, append([]*Message(nil), b.buffer[i+1:]...), true
+		}
+	}
+
+	return subscription, nil, false
+}
+
+// remove closes the Subscription. The caller must hold the lock.
+func (b *Broker) remove(subscription *Subscription) {
+	if _, ok := b.subscriptions[subscription]; ok {
+		delete(b.subscriptions, subscription)
+		close(subscription.messages)
+	}
+}
+
+// Subscription receives the Messages published by a Broker.
+type Subscription struct {
+	broker   *Broker
+	messages chan *Message
+}
+
+// Messages returns the channel of Messages, which is closed when the Subscription is closed or
+// falls too far behind.
+func (s *Subscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+// Close stops the Subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
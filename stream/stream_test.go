@@ -0,0 +1,44 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theantichris/events-api/objects"
+)
+
+func publish(broker *Broker, id int64) {
+	event := &objects.Event{ID: "event"}
+	broker.Publish(&objects.HistoryEntry{ID: id, Operation: objects.OpUpdate, After: objects.NewSnapshot(event)})
+}
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker(2)
+
+	for id := int64(1); id <= 3; id++ {
+		publish(broker, id)
+	}
+
+	_, replay, ok := broker.Subscribe(2)
+	assert.True(t, ok)
+	if assert.Len(t, replay, 1) {
+		assert.Equal(t, int64(3), replay[0].ID)
+	}
+
+	_, _, ok = broker.Subscribe(1)
+	assert.False(t, ok)
+
+	// Subscriptions that fall behind are closed.
+	subscription, _, _ := broker.Subscribe(0)
+	for id := int64(4); id <= 4+subscriptionSize; id++ {
+		publish(broker, id)
+	}
+
+	received := 0
+	for range subscription.Messages() {
+		received++
+	}
+
+	assert.Equal(t, subscriptionSize, received)
+	subscription.Close()
+}
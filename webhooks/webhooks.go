@@ -0,0 +1,293 @@
+// Package webhooks delivers the changes of Events to the Webhooks subscribed to them.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/theantichris/events-api/objects"
+	"github.com/theantichris/events-api/store"
+)
+
+// Headers of a delivery. The signature is "sha256=" and the hex HMAC-SHA256 of the timestamp, a
+// dot and the body, keyed with the Webhook's secret. The ID is the same for every attempt of the
+// same change, so receivers can drop duplicates.
+const (
+	IDHeader        = "X-Webhook-ID"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Options configures a Dispatcher, defaults are used for zero values.
+type Options struct {
+	Interval    time.Duration // how often to look for Deliveries
+	Batch       int           // most Deliveries attempted at once
+	Timeout     time.Duration // for each attempt
+	MaxAttempts int           // after which a Delivery is dead
+	Backoff     time.Duration // before the first retry, doubled for every retry after
+	MaxBackoff  time.Duration
+
+	// AllowPrivate lets Webhooks be delivered to loopback, private and link-local addresses,
+	// which are refused so Webhooks can't reach the internal network or cloud metadata.
+	AllowPrivate bool
+}
+
+// Defaults for the Options.
+const (
+	defaultInterval    = time.Second
+	defaultBatch       = 100
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 10
+	defaultBackoff     = 10 * time.Second
+	defaultMaxBackoff  = 6 * time.Hour
+)
+
+// Dispatcher POSTs the changes in the store's outbox to the Webhooks that want them, retrying
+// failed attempts with exponential backoff until they're delivered or dead.
+type Dispatcher struct {
+	store   store.EventStore
+	client  *http.Client
+	options Options
+}
+
+// NewDispatcher creates and returns a Dispatcher for the store.
+func NewDispatcher(st store.EventStore, options Options) *Dispatcher {
+	options.Interval = orDefault(options.Interval, defaultInterval)
+	options.Timeout = orDefault(options.Timeout, defaultTimeout)
+	options.Backoff = orDefault(options.Backoff, defaultBackoff)
+	options.MaxBackoff = orDefault(options.MaxBackoff, defaultMaxBackoff)
+
+	if options.Batch == 0 {
+		options.Batch = defaultBatch
+	}
+
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	if !options.AllowPrivate {
+		dialer.Control = refusePrivate
+	}
+
+	client := &http.Client{
+		// Without a proxy, so the address that's dialed is the Webhook's own.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: options.Timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		Timeout: options.Timeout,
+
+		// Redirects aren't followed, they could lead anywhere, so they fail the attempt.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &Dispatcher{store: st, client: client, options: options}
+}
+
+// Run dispatches every Interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			log.Println("Unable to dispatch webhooks:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch attempts the Deliveries that are due once and returns how many it attempted.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	// Claim the Deliveries for long enough to attempt all of them.
+	lease := time.Duration(d.options.Batch) * d.options.Timeout
+
+	deliveries, err := d.store.ClaimDeliveries(ctx, objects.ClaimDeliveriesRequest{Limit: d.options.Batch, Lease: lease})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, delivery := range deliveries {
+		d.attempt(ctx, delivery)
+
+		// Attempts cut short by shutting down are retried once their lease runs out.
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+
+		if err := d.store.CompleteDelivery(ctx, objects.CompleteDeliveryRequest{Delivery: delivery}); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt POSTs the Delivery to its Webhook and records the outcome in it.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *objects.Delivery) {
+	delivery.Attempts++
+
+	code, err := d.post(ctx, delivery)
+
+	now := time.Now()
+	delivery.LastCode = code
+
+	if err == nil {
+		delivery.Status = objects.DeliveryDelivered
+		delivery.DeliveredAt = now
+		delivery.LastError = ""
+
+		return
+	}
+
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= d.options.MaxAttempts {
+		delivery.Status = objects.DeliveryDead
+		return
+	}
+
+	delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts, d.options.Backoff, d.options.MaxBackoff))
+}
+
+func (d *Dispatcher) post(ctx context.Context, delivery *objects.Delivery) (int, error) {
+	if delivery.Webhook == nil {
+		return 0, fmt.Errorf("webhook %s not found", delivery.WebhookID)
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IDHeader, strconv.FormatInt(delivery.MessageID, 10))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// Drain the body so the connection is reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook responded %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
+
+// Sign returns the signature of a delivery's body sent at the Unix timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns how long to wait after the failed attempt, doubling from backoff up to max.
+func Backoff(attempts int, backoff, max time.Duration) time.Duration {
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+
+	if backoff > max {
+		return max
+	}
+
+	return backoff
+}
+
+// privateNetworks are the networks besides loopback, link-local and unspecified addresses that
+// Webhooks can't be delivered to: private (RFC 1918 and 4193) and shared (RFC 6598) addresses.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("fc00::/7"),
+}
+
+// refusePrivate is the Control of the Dialer of Webhooks, which refuses to connect to private
+// addresses. It runs after the host is resolved, so the address can't change after it's checked.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %s", host)
+	}
+
+	if Private(ip) {
+		return fmt.Errorf("refusing to deliver to private address %s", ip)
+	}
+
+	return nil
+}
+
+// Private reports whether the IP is a loopback, private, link-local, such as the cloud metadata
+// 169.254.169.254, multicast or unspecified address.
+func Private(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return network
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+
+	return d
+}
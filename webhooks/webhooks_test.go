@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/theantichris/events-api/objects"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", Sign("secret", 1700000000, []byte("{}")))
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Backoff(tt.attempts, time.Second, 10*time.Second))
+	}
+}
+
+func TestPrivate(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+		assert.True(t, Private(net.ParseIP(ip)), ip)
+	}
+
+	for _, ip := range []string{"93.184.216.34", "172.32.0.1", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.False(t, Private(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestPostRefusesPrivate(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Error("delivered to a private address")
+	}))
+	defer receiver.Close()
+
+	// Redirects to private addresses aren't followed either.
+	redirect := httptest.NewServer(http.RedirectHandler(receiver.URL, http.StatusFound))
+	defer redirect.Close()
+
+	delivery := &objects.Delivery{Payload: "{}", Webhook: &objects.Webhook{URL: receiver.URL}}
+
+	_, err := NewDispatcher(nil, Options{}).post(context.TODO(), delivery)
+	assert.Contains(t, err.Error(), "refusing to deliver to private address 127.0.0.1")
+
+	delivery.Webhook.URL = redirect.URL
+
+	code, err := NewDispatcher(nil, Options{AllowPrivate: true}).post(context.TODO(), delivery)
+	assert.Equal(t, http.StatusFound, code)
+	assert.NotNil(t, err)
+}